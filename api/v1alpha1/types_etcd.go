@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MetricsLevel defines the level of detail for etcd metrics.
+type MetricsLevel string
+
+const (
+	// Basic is the default metrics profile.
+	Basic MetricsLevel = "basic"
+	// Extensive exposes histograms and additional per-request metrics.
+	Extensive MetricsLevel = "extensive"
+)
+
+// CompactionMode defines the auto-compaction mode etcd runs with.
+type CompactionMode string
+
+const (
+	// Periodic compacts every AutoCompactionRetention interval, e.g. "30m".
+	Periodic CompactionMode = "periodic"
+	// Revision compacts once more than AutoCompactionRetention revisions have
+	// accumulated since the last compaction.
+	Revision CompactionMode = "revision"
+)
+
+// ConditionStatus is the status of a condition, following the same
+// True/False/Unknown tri-state used by upstream Kubernetes conditions.
+type ConditionStatus string
+
+// These are valid condition statuses.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// ConditionType is the type of an Etcd resource condition.
+type ConditionType string
+
+const (
+	// ConditionTypeAllMembersReady indicates whether all members of the etcd
+	// cluster are ready.
+	ConditionTypeAllMembersReady ConditionType = "AllMembersReady"
+	// ConditionTypeNoAlarms indicates whether any member of the etcd cluster
+	// has an active alarm (NOSPACE, CORRUPT, or user-defined).
+	ConditionTypeNoAlarms ConditionType = "NoAlarms"
+	// ConditionTypeCompactionSucceeded reflects the outcome of the most
+	// recent snapshot-compactor job.
+	ConditionTypeCompactionSucceeded ConditionType = "CompactionSucceeded"
+	// ConditionTypeConfigApplied indicates whether the etcd configuration
+	// rendered from the Etcd spec has been rolled out to all members.
+	ConditionTypeConfigApplied ConditionType = "ConfigApplied"
+)
+
+// EtcdMemberConditionStatus is the reported readiness of a single etcd
+// cluster member.
+type EtcdMemberConditionStatus string
+
+const (
+	// EtcdMemberStatusReady means the member has joined the cluster and is serving.
+	EtcdMemberStatusReady EtcdMemberConditionStatus = "Ready"
+	// EtcdMemberStatusNotReady means the member has not yet joined or is unreachable.
+	EtcdMemberStatusNotReady EtcdMemberConditionStatus = "NotReady"
+	// EtcdMemberStatusUnknown means readiness could not be determined.
+	EtcdMemberStatusUnknown EtcdMemberConditionStatus = "Unknown"
+)
+
+// SecretReference is a reference to a secret containing TLS material, along
+// with the key under which the relevant datum is stored.
+type SecretReference struct {
+	// Name is the name of the secret.
+	Name string `json:"name"`
+	// Namespace is the namespace of the secret.
+	Namespace string `json:"namespace,omitempty"`
+	// DataKey is the key in the secret's data that holds this datum.
+	// Defaults to a well-known key specific to the field referencing it.
+	// +optional
+	DataKey *string `json:"dataKey,omitempty"`
+}
+
+// TLSConfig configures the TLS transport security for either the client or
+// peer etcd endpoints.
+type TLSConfig struct {
+	// TLSCASecretRef refers to the secret containing the CA certificate.
+	TLSCASecretRef SecretReference `json:"tlsCASecretRef"`
+	// ServerTLSSecretRef refers to the secret containing the server certificate and key.
+	ServerTLSSecretRef SecretReference `json:"serverTLSSecretRef"`
+	// PeerSkipClientSANVerification disables SAN verification of client
+	// certificates presented on the peer port. This is only meaningful when
+	// this TLSConfig is used for peer transport security, and corresponds to
+	// etcd's `--experimental-peer-skip-client-san-verification` flag.
+	// +optional
+	PeerSkipClientSANVerification *bool `json:"peerSkipClientSANVerification,omitempty"`
+	// TLSMinVersion pins the minimum TLS version etcd accepts, e.g. "TLS1.3".
+	// Only honored against etcd binaries that support the `--tls-min-version`
+	// flag (v3.4.0+); ignored otherwise.
+	// +optional
+	TLSMinVersion *string `json:"tlsMinVersion,omitempty"`
+	// CipherSuites restricts the allowed TLS cipher suites by their Go
+	// `crypto/tls` constant names, e.g. "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384".
+	// Only honored against etcd binaries that support the `--cipher-suites`
+	// flag (v3.4.0+); ignored otherwise.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// EtcdMemberURLs declares the URLs advertised or listened on by a single,
+// named etcd cluster member.
+type EtcdMemberURLs struct {
+	// Name is the etcd member name, as used in --initial-cluster and related flags.
+	Name string `json:"name"`
+	// URLs is the list of URLs for this member.
+	URLs []string `json:"urls"`
+}
+
+// EtcdConfig contains the configuration of the etcd cluster members
+// themselves (as opposed to CommonConfig, which is shared with other
+// components such as the backup-restore sidecar).
+type EtcdConfig struct {
+	// Quota defines the etcd DB quota. Defaults to 8Gi.
+	// +optional
+	Quota *resource.Quantity `json:"quota,omitempty"`
+	// Metrics configures the metrics detail level. Defaults to "basic".
+	// +optional
+	Metrics *MetricsLevel `json:"metrics,omitempty"`
+	// ClientPort is the port etcd serves client traffic on.
+	// +optional
+	ClientPort *int32 `json:"clientPort,omitempty"`
+	// ServerPort is the port etcd serves peer traffic on.
+	// +optional
+	ServerPort *int32 `json:"serverPort,omitempty"`
+	// ClientUrlTLS configures TLS for the client endpoints. Client traffic is
+	// plaintext when unset.
+	// +optional
+	ClientUrlTLS *TLSConfig `json:"clientUrlTls,omitempty"`
+	// PeerUrlTLS configures TLS for the peer endpoints. Peer traffic is
+	// plaintext when unset.
+	// +optional
+	PeerUrlTLS *TLSConfig `json:"peerUrlTls,omitempty"`
+	// Image is the etcd container image reference, including tag.
+	// +optional
+	Image *string `json:"image,omitempty"`
+	// InitialCluster, when set, pins the exact `--initial-cluster` composition
+	// instead of deriving it from the peer service DNS names of this Etcd's
+	// own replicas. Needed for cross-region/stretched clusters or when
+	// bootstrapping against peers not managed by this resource.
+	// +optional
+	InitialCluster []EtcdMemberURLs `json:"initialCluster,omitempty"`
+	// PeerURLs, when set, pins the exact per-member URLs advertised on the
+	// peer port, instead of a templated value derived from the peer service.
+	// +optional
+	PeerURLs []EtcdMemberURLs `json:"peerUrls,omitempty"`
+	// ClientURLs, when set, pins the exact per-member URLs advertised on the
+	// client port, instead of a templated value derived from the peer service.
+	// +optional
+	ClientURLs []EtcdMemberURLs `json:"clientUrls,omitempty"`
+	// PeerListenURLs, when set, pins the exact per-member URLs the peer port
+	// is bound to, decoupling the listen address from PeerURLs (the advertised
+	// address). Needed when peers sit behind a load balancer or NAT.
+	// +optional
+	PeerListenURLs []EtcdMemberURLs `json:"peerListenUrls,omitempty"`
+	// ClientListenURLs is the client-port counterpart of PeerListenURLs.
+	// +optional
+	ClientListenURLs []EtcdMemberURLs `json:"clientListenUrls,omitempty"`
+	// HeartbeatIntervalMs is etcd's `--heartbeat-interval` in milliseconds.
+	// Defaults to 100. ElectionTimeoutMs must be at least 5x this value.
+	// +optional
+	HeartbeatIntervalMs *int64 `json:"heartbeatIntervalMs,omitempty"`
+	// ElectionTimeoutMs is etcd's `--election-timeout` in milliseconds.
+	// Defaults to 1000. Must be at least 5x HeartbeatIntervalMs, mirroring
+	// etcd's own startup validation.
+	// +optional
+	ElectionTimeoutMs *int64 `json:"electionTimeoutMs,omitempty"`
+	// SnapshotCount is etcd's `--snapshot-count`, the number of applied Raft
+	// entries to retain before triggering a snapshot. Defaults to 75000.
+	// +optional
+	SnapshotCount *int64 `json:"snapshotCount,omitempty"`
+	// MaxRequestBytes is etcd's `--max-request-bytes` in bytes. Defaults to
+	// etcd's own upstream default of 1.5MiB. Values above 10MiB are rejected.
+	// +optional
+	MaxRequestBytes *int64 `json:"maxRequestBytes,omitempty"`
+}
+
+// CommonConfig contains configuration shared between the etcd cluster
+// members and other components, such as the backup-restore sidecar.
+type CommonConfig struct {
+	// AutoCompactionMode is etcd's `--auto-compaction-mode`. Defaults to "periodic".
+	// +optional
+	AutoCompactionMode *CompactionMode `json:"autoCompactionMode,omitempty"`
+	// AutoCompactionRetention is etcd's `--auto-compaction-retention`. Its
+	// unit depends on AutoCompactionMode: a duration like "30m" for
+	// "periodic", or a revision count for "revision". Defaults to "30m".
+	// +optional
+	AutoCompactionRetention *string `json:"autoCompactionRetention,omitempty"`
+}
+
+// EtcdSpec defines the desired state of an Etcd cluster.
+type EtcdSpec struct {
+	// Replicas is the desired number of etcd cluster members.
+	Replicas int32 `json:"replicas"`
+	// Etcd configures the etcd cluster members.
+	Etcd EtcdConfig `json:"etcd"`
+	// Common configures settings shared with other components.
+	// +optional
+	Common CommonConfig `json:"common,omitempty"`
+}
+
+// EtcdMember is the observed state of a single etcd cluster member.
+type EtcdMember struct {
+	// Name is the etcd member name.
+	Name string `json:"name"`
+	// Status is the member's last observed readiness.
+	Status EtcdMemberConditionStatus `json:"status"`
+}
+
+// Condition represents an observation of an Etcd resource's state at a point in time.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status ConditionStatus `json:"status"`
+	// Reason is a brief, machine-readable explanation for the condition's last transition.
+	Reason string `json:"reason"`
+	// Message is a human-readable explanation of the condition.
+	Message string `json:"message"`
+	// LastTransitionTime is the last time the condition transitioned between statuses.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// EtcdStatus defines the observed state of an Etcd cluster.
+type EtcdStatus struct {
+	// Members is the observed state of each etcd cluster member.
+	// +optional
+	Members []EtcdMember `json:"members,omitempty"`
+	// Conditions represents the latest available observations of the Etcd's state.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the most recent Etcd.Generation for which the
+	// rendered configuration (including the knobs in EtcdConfig/CommonConfig)
+	// has been rolled out to every member.
+	// +optional
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Etcd is the Schema for the etcds API.
+type Etcd struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EtcdSpec   `json:"spec,omitempty"`
+	Status EtcdStatus `json:"status,omitempty"`
+}
+
+// GetPeerServiceName returns the name of the headless Service that fronts
+// the peer ports of the Etcd resource's members.
+func GetPeerServiceName(meta metav1.ObjectMeta) string {
+	return fmt.Sprintf("%s-peer", meta.Name)
+}
+
+// GetOrdinalPodName returns the name of the StatefulSet pod at the given
+// ordinal for the Etcd resource.
+func GetOrdinalPodName(meta metav1.ObjectMeta, ordinal int) string {
+	return fmt.Sprintf("%s-%d", meta.Name, ordinal)
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"fmt"
+	"reflect"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigFileName is the ConfigMap data key the rendered etcd config is stored
+// under when every member renders an identical config, which is the common
+// case: the default URL templates are expanded by the bootstrap script
+// running inside each member's own pod, so one shared file works for all of
+// them.
+const ConfigFileName = "etcd.conf.yaml"
+
+// Name returns the name of the ConfigMap holding the rendered etcd config for
+// the given Etcd resource.
+func Name(etcd *druidv1alpha1.Etcd) string {
+	return fmt.Sprintf("%s-config", etcd.Name)
+}
+
+// podConfigFileName returns the ConfigMap data key a member's own rendered
+// config is stored under once members diverge. The bootstrap entrypoint
+// running inside that member's pod must then select $POD_NAME.yaml instead of
+// the shared ConfigFileName.
+func podConfigFileName(podName string) string {
+	return fmt.Sprintf("%s.yaml", podName)
+}
+
+// SyncConfigMap renders the ConfigMap holding etcd's config for etcd.
+// Callers that also render the StatefulSet's pod template should pass this
+// ConfigMap's Data to statefulset.PodTemplateAnnotations, so a config change
+// here forces a StatefulSet rollout instead of silently going unapplied.
+func SyncConfigMap(etcd *druidv1alpha1.Etcd) (*corev1.ConfigMap, error) {
+	configs, err := prepareEtcdConfigsByPod(etcd)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := renderConfigMapData(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name(etcd),
+			Namespace: etcd.Namespace,
+		},
+		Data: data,
+	}, nil
+}
+
+// renderConfigMapData marshals configs into ConfigMap data entries. When
+// every member's config is identical it collapses them into a single
+// ConfigFileName entry shared by every pod; once any member diverges (e.g. a
+// PeerListenURLs override), each member's config is kept under its own
+// podConfigFileName entry instead, for a POD_NAME-aware entrypoint to select.
+func renderConfigMapData(configs map[string]*etcdConfig) (map[string]string, error) {
+	if !configsDiverge(configs) {
+		for _, cfg := range configs {
+			rendered, err := yaml.Marshal(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("marshaling etcd config: %w", err)
+			}
+			return map[string]string{ConfigFileName: string(rendered)}, nil
+		}
+		return map[string]string{}, nil
+	}
+
+	data := make(map[string]string, len(configs))
+	for podName, cfg := range configs {
+		rendered, err := yaml.Marshal(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling etcd config for pod %s: %w", podName, err)
+		}
+		data[podConfigFileName(podName)] = string(rendered)
+	}
+	return data, nil
+}
+
+// configsDiverge reports whether any two rendered per-pod configs differ,
+// i.e. whether a per-member URL override is actually in effect for this
+// cluster.
+func configsDiverge(configs map[string]*etcdConfig) bool {
+	var first *etcdConfig
+	for _, cfg := range configs {
+		if first == nil {
+			first = cfg
+			continue
+		}
+		if !reflect.DeepEqual(cfg, first) {
+			return true
+		}
+	}
+	return false
+}
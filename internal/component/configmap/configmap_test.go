@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"strings"
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncConfigMapSharedConfig(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default", UID: "abcdef12-3456"},
+		Spec:       druidv1alpha1.EtcdSpec{Replicas: 3},
+	}
+
+	cm, err := SyncConfigMap(etcd)
+	if err != nil {
+		t.Fatalf("SyncConfigMap() returned an unexpected error: %v", err)
+	}
+	if cm.Name != "etcd-main-config" {
+		t.Errorf("cm.Name = %q, want %q", cm.Name, "etcd-main-config")
+	}
+	if len(cm.Data) != 1 {
+		t.Fatalf("expected a single shared config entry, got %d: %v", len(cm.Data), cm.Data)
+	}
+	if _, ok := cm.Data[ConfigFileName]; !ok {
+		t.Errorf("expected data key %q, got keys %v", ConfigFileName, cm.Data)
+	}
+}
+
+func TestSyncConfigMapPerPodConfig(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default", UID: "abcdef12-3456"},
+		Spec: druidv1alpha1.EtcdSpec{
+			Replicas: 2,
+			Etcd: druidv1alpha1.EtcdConfig{
+				PeerListenURLs: []druidv1alpha1.EtcdMemberURLs{
+					{Name: "etcd-main-0", URLs: []string{"http://10.0.0.1:2380"}},
+					{Name: "etcd-main-1", URLs: []string{"http://10.0.0.2:2380"}},
+				},
+			},
+		},
+	}
+
+	cm, err := SyncConfigMap(etcd)
+	if err != nil {
+		t.Fatalf("SyncConfigMap() returned an unexpected error: %v", err)
+	}
+	if len(cm.Data) != 2 {
+		t.Fatalf("expected one config entry per pod, got %d: %v", len(cm.Data), cm.Data)
+	}
+	for _, podName := range []string{"etcd-main-0", "etcd-main-1"} {
+		key := podConfigFileName(podName)
+		rendered, ok := cm.Data[key]
+		if !ok {
+			t.Errorf("expected data key %q, got keys %v", key, cm.Data)
+			continue
+		}
+		if !strings.Contains(rendered, "listen-peer-urls") {
+			t.Errorf("rendered config for %s does not look like a config: %s", podName, rendered)
+		}
+	}
+}
+
+func TestSyncConfigMapPropagatesRenderError(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default", UID: "abcdef12-3456"},
+		Spec: druidv1alpha1.EtcdSpec{
+			Replicas: 1,
+			Etcd: druidv1alpha1.EtcdConfig{
+				HeartbeatIntervalMs: int64Ptr(100),
+				ElectionTimeoutMs:   int64Ptr(200), // too close to HeartbeatIntervalMs, see ValidateEtcdConfig
+			},
+		},
+	}
+
+	if _, err := SyncConfigMap(etcd); err == nil {
+		t.Errorf("expected SyncConfigMap() to propagate the rendering error, got nil")
+	}
+}
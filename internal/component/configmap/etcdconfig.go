@@ -12,6 +12,8 @@ import (
 	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
 	"github.com/gardener/etcd-druid/internal/common"
 	"github.com/gardener/etcd-druid/internal/utils"
+	"github.com/gardener/etcd-druid/pkg/autocompaction"
+	"github.com/gardener/etcd-druid/pkg/features"
 	"k8s.io/utils/pointer"
 )
 
@@ -22,9 +24,20 @@ const (
 	defaultInitialClusterToken     = "etcd-cluster"
 	defaultInitialClusterState     = "new"
 	// For more information refer to https://etcd.io/docs/v3.4/op-guide/maintenance/#raft-log-retention
-	// TODO: Ideally this should be made configurable via Etcd resource as this has a direct impact on the memory requirements for etcd container.
 	// which in turn is influenced by the size of objects that are getting stored in etcd.
 	defaultSnapshotCount = 75000
+	// defaultHeartbeatIntervalMs is etcd's own upstream default for `--heartbeat-interval`.
+	defaultHeartbeatIntervalMs = 100
+	// defaultElectionTimeoutMs is etcd's own upstream default for `--election-timeout`.
+	defaultElectionTimeoutMs = 1000
+	// defaultMaxRequestBytes is etcd's own upstream default for `--max-request-bytes`.
+	defaultMaxRequestBytes = 1.5 * 1024 * 1024
+	// minHeartbeatToElectionFactor is the minimum factor by which the election timeout
+	// must exceed the heartbeat interval, mirroring etcd's own startup validation.
+	minHeartbeatToElectionFactor = 5
+	// maxRequestBytesUpperBound caps MaxRequestBytes to etcd's documented hard ceiling,
+	// beyond which etcd refuses to start. See https://etcd.io/docs/v3.5/dev-guide/limit/.
+	maxRequestBytesUpperBound = 10 * 1024 * 1024
 )
 
 var (
@@ -43,6 +56,9 @@ type etcdConfig struct {
 	DataDir                 string                       `yaml:"data-dir"`
 	Metrics                 druidv1alpha1.MetricsLevel   `yaml:"metrics"`
 	SnapshotCount           int                          `yaml:"snapshot-count"`
+	HeartbeatIntervalMs     int                          `yaml:"heartbeat-interval"`
+	ElectionTimeoutMs       int                          `yaml:"election-timeout"`
+	MaxRequestBytes         uint                         `yaml:"max-request-bytes"`
 	EnableV2                bool                         `yaml:"enable-v2"`
 	QuotaBackendBytes       int64                        `yaml:"quota-backend-bytes"`
 	InitialClusterToken     string                       `yaml:"initial-cluster-token"`
@@ -59,33 +75,117 @@ type etcdConfig struct {
 }
 
 type securityConfig struct {
-	CertFile       string `yaml:"cert-file,omitempty"`
-	KeyFile        string `yaml:"key-file,omitempty"`
-	ClientCertAuth bool   `yaml:"client-cert-auth,omitempty"`
-	TrustedCAFile  string `yaml:"trusted-ca-file,omitempty"`
-	AutoTLS        bool   `yaml:"auto-tls"`
+	CertFile                      string `yaml:"cert-file,omitempty"`
+	KeyFile                       string `yaml:"key-file,omitempty"`
+	ClientCertAuth                bool   `yaml:"client-cert-auth,omitempty"`
+	TrustedCAFile                 string `yaml:"trusted-ca-file,omitempty"`
+	AutoTLS                       bool   `yaml:"auto-tls"`
+	PeerSkipClientSANVerification bool   `yaml:"experimental-peer-skip-client-san-verification,omitempty"`
+	TLSMinVersion                 string `yaml:"tls-min-version,omitempty"`
+	CipherSuites                  string `yaml:"cipher-suites,omitempty"`
 }
 
-func createEtcdConfig(etcd *druidv1alpha1.Etcd) *etcdConfig {
-	clientScheme, clientSecurityConfig := getSchemeAndSecurityConfig(etcd.Spec.Etcd.ClientUrlTLS, common.VolumeMountPathEtcdCA, common.VolumeMountPathEtcdServerTLS)
-	peerScheme, peerSecurityConfig := getSchemeAndSecurityConfig(etcd.Spec.Etcd.PeerUrlTLS, common.VolumeMountPathEtcdPeerCA, common.VolumeMountPathEtcdPeerServerTLS)
+// minEtcdVersionForCipherSuiteFlags is the etcd release that introduced the
+// `--tls-min-version` and `--cipher-suites` flags. Emitting them against an
+// older binary makes etcd fail fast on startup with "unknown flag".
+const minEtcdVersionForCipherSuiteFlags = "3.4.0"
+
+// minEtcdVersionForPeerSkipSANVerification is the etcd release that
+// introduced `--experimental-peer-skip-client-san-verification`. Emitting it
+// against an older binary makes etcd fail fast on startup with "unknown flag".
+const minEtcdVersionForPeerSkipSANVerification = "3.3.0"
+
+// etcdVersionSupportsCipherSuiteFlags reports whether the given etcd image tag
+// is recent enough to accept the `--tls-min-version` and `--cipher-suites` flags.
+func etcdVersionSupportsCipherSuiteFlags(etcdVersion string) bool {
+	return etcdVersionAtLeast(etcdVersion, minEtcdVersionForCipherSuiteFlags)
+}
+
+// etcdVersionAtLeast reports whether etcdVersion is greater than or equal to
+// min, both given as dotted "major.minor.patch" strings. An unparseable
+// etcdVersion or min conservatively reports false, so that we never emit a
+// flag the running binary might reject.
+func etcdVersionAtLeast(etcdVersion, min string) bool {
+	got, err := parseMajorMinorPatch(etcdVersion)
+	if err != nil {
+		return false
+	}
+	want, err := parseMajorMinorPatch(min)
+	if err != nil {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return got[i] > want[i]
+		}
+	}
+	return true
+}
+
+// parseMajorMinorPatch parses a dotted "major.minor.patch" version string,
+// ignoring any leading "v" and trailing pre-release/build metadata.
+func parseMajorMinorPatch(version string) ([3]int, error) {
+	var parts [3]int
+	version = strings.TrimPrefix(version, "v")
+	if idx := strings.IndexAny(version, "-+"); idx != -1 {
+		version = version[:idx]
+	}
+	segments := strings.Split(version, ".")
+	if len(segments) != 3 {
+		return parts, fmt.Errorf("invalid etcd version %q, expected major.minor.patch", version)
+	}
+	for i, segment := range segments {
+		n, err := strconv.Atoi(segment)
+		if err != nil {
+			return parts, fmt.Errorf("invalid etcd version %q: %w", version, err)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// createEtcdConfig renders the etcd config for a single member, identified by
+// podName. Members normally share an identical rendered config (the default
+// URL templates are expanded against "${POD_NAME}" by the bootstrap script
+// running inside that member's own pod), but once any of the PeerURLs,
+// ClientURLs, PeerListenURLs or ClientListenURLs overrides are set, each
+// member can end up with distinct advertise/listen URLs, so the config must
+// be rendered per pod rather than once for the whole cluster. See
+// prepareEtcdConfigsByPod, which does so for every member.
+//
+// createEtcdConfig rejects tuning combinations ValidateEtcdConfig would
+// reject, rather than rendering a config etcd itself would refuse to start
+// with, since nothing else in this tree calls ValidateEtcdConfig ahead of
+// time.
+func createEtcdConfig(etcd *druidv1alpha1.Etcd, podName string) (*etcdConfig, error) {
+	if err := ValidateEtcdConfig(etcd); err != nil {
+		return nil, err
+	}
+
+	etcdVersion := getEtcdVersion(etcd)
+	clientScheme, clientSecurityConfig := getSchemeAndSecurityConfig(etcd.Spec.Etcd.ClientUrlTLS, common.VolumeMountPathEtcdCA, common.VolumeMountPathEtcdServerTLS, etcdVersion, false)
+	peerScheme, peerSecurityConfig := getSchemeAndSecurityConfig(etcd.Spec.Etcd.PeerUrlTLS, common.VolumeMountPathEtcdPeerCA, common.VolumeMountPathEtcdPeerServerTLS, etcdVersion, true)
 	peerSvcName := druidv1alpha1.GetPeerServiceName(etcd.ObjectMeta)
+	autoCompactionMode, autoCompactionRetention := getAutoCompactionModeAndRetention(etcd)
 	cfg := &etcdConfig{
 		Name:                    fmt.Sprintf("etcd-%s", etcd.UID[:6]),
 		DataDir:                 defaultDataDir,
 		Metrics:                 utils.TypeDeref(etcd.Spec.Etcd.Metrics, druidv1alpha1.Basic),
-		SnapshotCount:           defaultSnapshotCount,
+		SnapshotCount:           int(getSnapshotCount(etcd)),
+		HeartbeatIntervalMs:     int(getHeartbeatIntervalMs(etcd)),
+		ElectionTimeoutMs:       int(getElectionTimeoutMs(etcd)),
+		MaxRequestBytes:         getMaxRequestBytes(etcd),
 		EnableV2:                false,
 		QuotaBackendBytes:       getDBQuotaBytes(etcd),
 		InitialClusterToken:     defaultInitialClusterToken,
 		InitialClusterState:     defaultInitialClusterState,
 		InitialCluster:          prepareInitialCluster(etcd, peerScheme),
-		AutoCompactionMode:      utils.TypeDeref(etcd.Spec.Common.AutoCompactionMode, druidv1alpha1.Periodic),
-		AutoCompactionRetention: utils.TypeDeref(etcd.Spec.Common.AutoCompactionRetention, defaultAutoCompactionRetention),
-		ListenPeerUrls:          fmt.Sprintf("%s://0.0.0.0:%d", peerScheme, utils.TypeDeref(etcd.Spec.Etcd.ServerPort, common.DefaultPortEtcdPeer)),
-		ListenClientUrls:        fmt.Sprintf("%s://0.0.0.0:%d", clientScheme, utils.TypeDeref(etcd.Spec.Etcd.ClientPort, common.DefaultPortEtcdClient)),
-		AdvertisePeerUrls:       preparePeerURLs(etcd, peerScheme, peerSvcName),
-		AdvertiseClientUrls:     prepareClientURLs(etcd, clientScheme, peerSvcName),
+		AutoCompactionMode:      autoCompactionMode,
+		AutoCompactionRetention: autoCompactionRetention,
+		ListenPeerUrls:          prepareMemberURLs(podName, etcd.Spec.Etcd.PeerListenURLs, fmt.Sprintf("%s://0.0.0.0:%d", peerScheme, utils.TypeDeref(etcd.Spec.Etcd.ServerPort, common.DefaultPortEtcdPeer))),
+		ListenClientUrls:        prepareMemberURLs(podName, etcd.Spec.Etcd.ClientListenURLs, fmt.Sprintf("%s://0.0.0.0:%d", clientScheme, utils.TypeDeref(etcd.Spec.Etcd.ClientPort, common.DefaultPortEtcdClient))),
+		AdvertisePeerUrls:       prepareMemberURLs(podName, etcd.Spec.Etcd.PeerURLs, fmt.Sprintf("%s@%s@%s@%d", peerScheme, peerSvcName, etcd.Namespace, utils.TypeDeref(etcd.Spec.Etcd.ServerPort, common.DefaultPortEtcdPeer))),
+		AdvertiseClientUrls:     prepareMemberURLs(podName, etcd.Spec.Etcd.ClientURLs, fmt.Sprintf("%s@%s@%s@%d", clientScheme, peerSvcName, etcd.Namespace, utils.TypeDeref(etcd.Spec.Etcd.ClientPort, common.DefaultPortEtcdClient))),
 	}
 	if peerSecurityConfig != nil {
 		cfg.PeerSecurity = *peerSecurityConfig
@@ -94,7 +194,54 @@ func createEtcdConfig(etcd *druidv1alpha1.Etcd) *etcdConfig {
 		cfg.ClientSecurity = *clientSecurityConfig
 	}
 
-	return cfg
+	return cfg, nil
+}
+
+// prepareEtcdConfigsByPod renders the etcd config separately for every member
+// of the cluster, keyed by pod name. Callers should diff the returned configs
+// against each other: when none of the per-member URL overrides are set they
+// are all identical and a single shared ConfigMap suffices, but once members
+// diverge (e.g. distinct PeerListenURLs per member) each pod needs its own
+// rendered ConfigMap key.
+func prepareEtcdConfigsByPod(etcd *druidv1alpha1.Etcd) (map[string]*etcdConfig, error) {
+	configs := make(map[string]*etcdConfig, etcd.Spec.Replicas)
+	for i := 0; i < int(etcd.Spec.Replicas); i++ {
+		podName := druidv1alpha1.GetOrdinalPodName(etcd.ObjectMeta, i)
+		cfg, err := createEtcdConfig(etcd, podName)
+		if err != nil {
+			return nil, err
+		}
+		configs[podName] = cfg
+	}
+	return configs, nil
+}
+
+// getAutoCompactionModeAndRetention returns the auto-compaction mode and
+// retention to render, as a single, mutually consistent pair. It only
+// switches to "revision" mode (and a revision-count retention) once the
+// adaptive estimator (see pkg/autocompaction.Updater) has actually recorded a
+// value in autocompaction.AnnotationAdaptiveCompactionRetention; until then,
+// AdaptiveAutoCompaction has no effect and the statically configured
+// mode/retention are rendered unchanged, so that a freshly enabled feature
+// gate never pairs "revision" mode with a duration-formatted retention etcd
+// cannot parse.
+func getAutoCompactionModeAndRetention(etcd *druidv1alpha1.Etcd) (druidv1alpha1.CompactionMode, string) {
+	staticMode := utils.TypeDeref(etcd.Spec.Common.AutoCompactionMode, druidv1alpha1.Periodic)
+	staticRetention := utils.TypeDeref(etcd.Spec.Common.AutoCompactionRetention, defaultAutoCompactionRetention)
+
+	if !features.FeatureGate.Enabled(features.AdaptiveAutoCompaction) {
+		return staticMode, staticRetention
+	}
+
+	retention, ok := etcd.Annotations[autocompaction.AnnotationAdaptiveCompactionRetention]
+	if !ok || retention == "" {
+		return staticMode, staticRetention
+	}
+	if _, err := strconv.ParseInt(retention, 10, 64); err != nil {
+		return staticMode, staticRetention
+	}
+
+	return druidv1alpha1.Revision, retention
 }
 
 func getDBQuotaBytes(etcd *druidv1alpha1.Etcd) int64 {
@@ -105,30 +252,110 @@ func getDBQuotaBytes(etcd *druidv1alpha1.Etcd) int64 {
 	return dbQuotaBytes
 }
 
-func getSchemeAndSecurityConfig(tlsConfig *druidv1alpha1.TLSConfig, caPath, serverTLSPath string) (string, *securityConfig) {
+func getSnapshotCount(etcd *druidv1alpha1.Etcd) int64 {
+	return utils.TypeDeref(etcd.Spec.Etcd.SnapshotCount, int64(defaultSnapshotCount))
+}
+
+func getHeartbeatIntervalMs(etcd *druidv1alpha1.Etcd) int64 {
+	return utils.TypeDeref(etcd.Spec.Etcd.HeartbeatIntervalMs, int64(defaultHeartbeatIntervalMs))
+}
+
+func getElectionTimeoutMs(etcd *druidv1alpha1.Etcd) int64 {
+	return utils.TypeDeref(etcd.Spec.Etcd.ElectionTimeoutMs, int64(defaultElectionTimeoutMs))
+}
+
+func getMaxRequestBytes(etcd *druidv1alpha1.Etcd) uint {
+	return uint(utils.TypeDeref(etcd.Spec.Etcd.MaxRequestBytes, int64(defaultMaxRequestBytes)))
+}
+
+// ValidateEtcdConfig rejects etcd tuning combinations that the etcd binary
+// itself would refuse to start with. createEtcdConfig calls this on every
+// render so that misconfiguration surfaces as a ConfigMap-rendering error
+// instead of a crash-looping etcd container; it is also exported so that a
+// future validating webhook can reject the same combinations at admission
+// time, before they are ever written to the Etcd resource.
+func ValidateEtcdConfig(etcd *druidv1alpha1.Etcd) error {
+	heartbeat := getHeartbeatIntervalMs(etcd)
+	election := getElectionTimeoutMs(etcd)
+	if heartbeat <= 0 {
+		return fmt.Errorf("heartbeatIntervalMs must be a positive value, got %d", heartbeat)
+	}
+	if election <= 0 {
+		return fmt.Errorf("electionTimeoutMs must be a positive value, got %d", election)
+	}
+	if election < heartbeat*minHeartbeatToElectionFactor {
+		return fmt.Errorf("electionTimeoutMs (%d) must be at least %d times heartbeatIntervalMs (%d)", election, minHeartbeatToElectionFactor, heartbeat)
+	}
+
+	maxRequestBytes := getMaxRequestBytes(etcd)
+	if maxRequestBytes > maxRequestBytesUpperBound {
+		return fmt.Errorf("maxRequestBytes (%d) must not exceed %d", maxRequestBytes, maxRequestBytesUpperBound)
+	}
+
+	if snapshotCount := getSnapshotCount(etcd); snapshotCount <= 0 {
+		return fmt.Errorf("snapshotCount must be a positive value, got %d", snapshotCount)
+	}
+
+	return nil
+}
+
+func getSchemeAndSecurityConfig(tlsConfig *druidv1alpha1.TLSConfig, caPath, serverTLSPath string, etcdVersion string, isPeer bool) (string, *securityConfig) {
 	if tlsConfig != nil {
 		const defaultTLSCASecretKey = "ca.crt"
-		return "https", &securityConfig{
+		cfg := &securityConfig{
 			CertFile:       fmt.Sprintf("%s/tls.crt", serverTLSPath),
 			KeyFile:        fmt.Sprintf("%s/tls.key", serverTLSPath),
 			ClientCertAuth: true,
 			TrustedCAFile:  fmt.Sprintf("%s/%s", caPath, utils.TypeDeref(tlsConfig.TLSCASecretRef.DataKey, defaultTLSCASecretKey)),
 			AutoTLS:        false,
 		}
+		if isPeer && etcdVersionAtLeast(etcdVersion, minEtcdVersionForPeerSkipSANVerification) {
+			cfg.PeerSkipClientSANVerification = utils.TypeDeref(tlsConfig.PeerSkipClientSANVerification, false)
+		}
+		if etcdVersionSupportsCipherSuiteFlags(etcdVersion) {
+			cfg.TLSMinVersion = utils.TypeDeref(tlsConfig.TLSMinVersion, "")
+			if len(tlsConfig.CipherSuites) > 0 {
+				cfg.CipherSuites = strings.Join(tlsConfig.CipherSuites, ",")
+			}
+		}
+		return "https", cfg
 	}
 	return "http", nil
 }
 
+// getEtcdVersion returns the etcd release tag running in the etcd container,
+// parsed from its image reference (e.g. "europe-docker.pkg.dev/.../etcd:v3.5.9" -> "3.5.9").
+func getEtcdVersion(etcd *druidv1alpha1.Etcd) string {
+	image := utils.TypeDeref(etcd.Spec.Etcd.Image, "")
+	if idx := strings.LastIndex(image, ":"); idx != -1 {
+		return strings.TrimPrefix(image[idx+1:], "v")
+	}
+	return ""
+}
+
 func prepareInitialCluster(etcd *druidv1alpha1.Etcd, peerScheme string) string {
 	builder := strings.Builder{}
 
-	if etcd.Spec.Etcd.InitialCluster != nil {
+	switch {
+	case etcd.Spec.Etcd.InitialCluster != nil:
+		// An explicit initial-cluster composition always wins, e.g. when
+		// bootstrapping against peers that are not managed by this Etcd
+		// resource at all.
 		for _, member := range etcd.Spec.Etcd.InitialCluster {
 			for _, url := range member.URLs {
 				builder.WriteString(fmt.Sprintf("%s=%s,", member.Name, url))
 			}
 		}
-	} else {
+	case etcd.Spec.Etcd.PeerURLs != nil:
+		// Reuse the per-member advertise overrides: the URLs a member
+		// advertises to its peers are exactly the URLs the rest of the
+		// cluster should dial it on.
+		for _, member := range etcd.Spec.Etcd.PeerURLs {
+			for _, url := range member.URLs {
+				builder.WriteString(fmt.Sprintf("%s=%s,", member.Name, url))
+			}
+		}
+	default:
 		domainName := fmt.Sprintf("%s.%s.%s", druidv1alpha1.GetPeerServiceName(etcd.ObjectMeta), etcd.Namespace, "svc")
 		serverPort := strconv.Itoa(int(pointer.Int32Deref(etcd.Spec.Etcd.ServerPort, common.DefaultPortEtcdPeer)))
 		for i := 0; i < int(etcd.Spec.Replicas); i++ {
@@ -139,34 +366,27 @@ func prepareInitialCluster(etcd *druidv1alpha1.Etcd, peerScheme string) string {
 	return strings.Trim(builder.String(), ",")
 }
 
-func preparePeerURLs(etcd *druidv1alpha1.Etcd, peerScheme, peerSvcName string) string {
-	if etcd.Spec.Etcd.PeerURLs != nil {
-		builder := strings.Builder{}
-
-		for _, member := range etcd.Spec.Etcd.PeerURLs {
-			for _, url := range member.URLs {
-				builder.WriteString(fmt.Sprintf("%s=%s,", member.Name, url))
-			}
-		}
-
-		return strings.Trim(builder.String(), ",")
+// prepareMemberURLs renders the value of a per-member, single-URL-list etcd
+// flag (--initial-advertise-peer-urls, --advertise-client-urls,
+// --listen-peer-urls or --listen-client-urls). Unlike --initial-cluster,
+// these flags take a plain comma-separated URL list scoped to the member
+// the config is being rendered for, with no "name=" prefix, so overrides is
+// searched for the entry matching podName and only that member's URLs are
+// joined; when no override matches, defaultURL is used as-is.
+func prepareMemberURLs(podName string, overrides []druidv1alpha1.EtcdMemberURLs, defaultURL string) string {
+	if urls, ok := findMemberURLs(overrides, podName); ok {
+		return strings.Join(urls, ",")
 	}
-
-	return fmt.Sprintf("%s@%s@%s@%d", peerScheme, peerSvcName, etcd.Namespace, utils.TypeDeref(etcd.Spec.Etcd.ServerPort, common.DefaultPortEtcdPeer))
+	return defaultURL
 }
 
-func prepareClientURLs(etcd *druidv1alpha1.Etcd, clientScheme, peerSvcName string) string {
-	if etcd.Spec.Etcd.ClientURLs != nil {
-		builder := strings.Builder{}
-
-		for _, member := range etcd.Spec.Etcd.ClientURLs {
-			for _, url := range member.URLs {
-				builder.WriteString(fmt.Sprintf("%s=%s,", member.Name, url))
-			}
+// findMemberURLs looks up the URLs of the member named podName within
+// overrides.
+func findMemberURLs(overrides []druidv1alpha1.EtcdMemberURLs, podName string) ([]string, bool) {
+	for _, member := range overrides {
+		if member.Name == podName {
+			return member.URLs, true
 		}
-
-		return strings.Trim(builder.String(), ",")
 	}
-
-	return fmt.Sprintf("%s@%s@%s@%d", clientScheme, peerSvcName, etcd.Namespace, utils.TypeDeref(etcd.Spec.Etcd.ClientPort, common.DefaultPortEtcdClient))
+	return nil, false
 }
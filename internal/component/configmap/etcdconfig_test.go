@@ -0,0 +1,310 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestValidateEtcdConfig(t *testing.T) {
+	tests := map[string]struct {
+		etcdConfig druidv1alpha1.EtcdConfig
+		wantErr    bool
+	}{
+		"defaults are valid": {
+			etcdConfig: druidv1alpha1.EtcdConfig{},
+		},
+		"heartbeat must be positive": {
+			etcdConfig: druidv1alpha1.EtcdConfig{HeartbeatIntervalMs: int64Ptr(0)},
+			wantErr:    true,
+		},
+		"election must be positive": {
+			etcdConfig: druidv1alpha1.EtcdConfig{ElectionTimeoutMs: int64Ptr(0)},
+			wantErr:    true,
+		},
+		"election too close to heartbeat": {
+			etcdConfig: druidv1alpha1.EtcdConfig{HeartbeatIntervalMs: int64Ptr(100), ElectionTimeoutMs: int64Ptr(200)},
+			wantErr:    true,
+		},
+		"election far enough from heartbeat": {
+			etcdConfig: druidv1alpha1.EtcdConfig{HeartbeatIntervalMs: int64Ptr(100), ElectionTimeoutMs: int64Ptr(500)},
+		},
+		"max request bytes exceeds upper bound": {
+			etcdConfig: druidv1alpha1.EtcdConfig{MaxRequestBytes: int64Ptr(20 * 1024 * 1024)},
+			wantErr:    true,
+		},
+		"snapshot count must be positive": {
+			etcdConfig: druidv1alpha1.EtcdConfig{SnapshotCount: int64Ptr(0)},
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			etcd := &druidv1alpha1.Etcd{Spec: druidv1alpha1.EtcdSpec{Etcd: tc.etcdConfig}}
+			err := ValidateEtcdConfig(etcd)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPrepareMemberURLs(t *testing.T) {
+	overrides := []druidv1alpha1.EtcdMemberURLs{
+		{Name: "etcd-main-0", URLs: []string{"https://10.0.0.1:2380"}},
+		{Name: "etcd-main-1", URLs: []string{"https://10.0.0.2:2380", "https://10.0.0.2:2381"}},
+	}
+
+	tests := map[string]struct {
+		podName string
+		want    string
+	}{
+		"single-URL override for this pod, no name= prefix": {
+			podName: "etcd-main-0",
+			want:    "https://10.0.0.1:2380",
+		},
+		"multi-URL override joined with a plain comma, no name= prefix": {
+			podName: "etcd-main-1",
+			want:    "https://10.0.0.2:2380,https://10.0.0.2:2381",
+		},
+		"no override for this pod falls back to the default": {
+			podName: "etcd-main-2",
+			want:    "https://0.0.0.0:2380",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := prepareMemberURLs(tc.podName, overrides, "https://0.0.0.0:2380")
+			if got != tc.want {
+				t.Errorf("prepareMemberURLs() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrepareEtcdConfigsByPod(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default", UID: "abcdef12-3456"},
+		Spec: druidv1alpha1.EtcdSpec{
+			Replicas: 2,
+			Etcd: druidv1alpha1.EtcdConfig{
+				PeerListenURLs: []druidv1alpha1.EtcdMemberURLs{
+					{Name: "etcd-main-0", URLs: []string{"http://10.0.0.1:2380"}},
+					{Name: "etcd-main-1", URLs: []string{"http://10.0.0.2:2380"}},
+				},
+			},
+		},
+	}
+
+	configs, err := prepareEtcdConfigsByPod(etcd)
+	if err != nil {
+		t.Fatalf("prepareEtcdConfigsByPod() returned an unexpected error: %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 rendered configs, got %d", len(configs))
+	}
+	if got := configs["etcd-main-0"].ListenPeerUrls; got != "http://10.0.0.1:2380" {
+		t.Errorf("etcd-main-0 ListenPeerUrls = %q, want %q", got, "http://10.0.0.1:2380")
+	}
+	if got := configs["etcd-main-1"].ListenPeerUrls; got != "http://10.0.0.2:2380" {
+		t.Errorf("etcd-main-1 ListenPeerUrls = %q, want %q", got, "http://10.0.0.2:2380")
+	}
+	if configs["etcd-main-0"].ListenPeerUrls == configs["etcd-main-1"].ListenPeerUrls {
+		t.Errorf("expected members with distinct PeerListenURLs overrides to render distinct configs")
+	}
+}
+
+func TestCreateEtcdConfigRejectsInvalidTuning(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default", UID: "abcdef12-3456"},
+		Spec: druidv1alpha1.EtcdSpec{
+			Replicas: 1,
+			Etcd: druidv1alpha1.EtcdConfig{
+				HeartbeatIntervalMs: int64Ptr(100),
+				ElectionTimeoutMs:   int64Ptr(200), // too close to HeartbeatIntervalMs, see ValidateEtcdConfig
+			},
+		},
+	}
+
+	if _, err := createEtcdConfig(etcd, "etcd-main-0"); err == nil {
+		t.Errorf("createEtcdConfig() with an invalid heartbeat/election pair: expected an error, got nil")
+	}
+	if _, err := prepareEtcdConfigsByPod(etcd); err == nil {
+		t.Errorf("prepareEtcdConfigsByPod() with an invalid heartbeat/election pair: expected an error, got nil")
+	}
+}
+
+func TestParseMajorMinorPatch(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		want    [3]int
+		wantErr bool
+	}{
+		"plain version":              {version: "3.5.9", want: [3]int{3, 5, 9}},
+		"leading v is stripped":      {version: "v3.4.20", want: [3]int{3, 4, 20}},
+		"pre-release suffix ignored": {version: "3.5.0-rc.1", want: [3]int{3, 5, 0}},
+		"build metadata ignored":     {version: "3.5.0+build5", want: [3]int{3, 5, 0}},
+		"empty string is invalid":    {version: "", wantErr: true},
+		"missing patch segment":      {version: "3.5", wantErr: true},
+		"non-numeric segment":        {version: "3.x.0", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseMajorMinorPatch(tc.version)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseMajorMinorPatch(%q) = %v, want %v", tc.version, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEtcdVersionAtLeast(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		min     string
+		want    bool
+	}{
+		"newer patch satisfies":        {version: "3.4.1", min: "3.4.0", want: true},
+		"exact match satisfies":        {version: "3.4.0", min: "3.4.0", want: true},
+		"older minor does not satisfy": {version: "3.3.9", min: "3.4.0", want: false},
+		"newer major satisfies":        {version: "4.0.0", min: "3.4.0", want: true},
+		"unparseable version":          {version: "not-a-version", min: "3.4.0", want: false},
+		"empty version":                {version: "", min: "3.4.0", want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := etcdVersionAtLeast(tc.version, tc.min); got != tc.want {
+				t.Errorf("etcdVersionAtLeast(%q, %q) = %v, want %v", tc.version, tc.min, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetEtcdVersion(t *testing.T) {
+	tests := map[string]struct {
+		image string
+		want  string
+	}{
+		"versioned tag with v prefix": {
+			image: "europe-docker.pkg.dev/gardener-project/releases/3rd/etcd:v3.5.9",
+			want:  "3.5.9",
+		},
+		"versioned tag without v prefix": {
+			image: "registry.example.com/etcd:3.4.20",
+			want:  "3.4.20",
+		},
+		"no image configured": {
+			image: "",
+			want:  "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			etcd := &druidv1alpha1.Etcd{}
+			if tc.image != "" {
+				etcd.Spec.Etcd.Image = &tc.image
+			}
+			if got := getEtcdVersion(etcd); got != tc.want {
+				t.Errorf("getEtcdVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetSchemeAndSecurityConfig(t *testing.T) {
+	caPath, serverTLSPath := "/var/etcd/ssl/ca", "/var/etcd/ssl/server"
+	tlsConfig := &druidv1alpha1.TLSConfig{
+		TLSCASecretRef:                druidv1alpha1.SecretReference{Name: "etcd-ca"},
+		PeerSkipClientSANVerification: boolPtr(true),
+		TLSMinVersion:                 stringPtr("TLS1.2"),
+		CipherSuites:                  []string{"TLS_AES_128_GCM_SHA256"},
+	}
+
+	t.Run("no TLS configured renders http with no security config", func(t *testing.T) {
+		scheme, cfg := getSchemeAndSecurityConfig(nil, caPath, serverTLSPath, "3.5.9", false)
+		if scheme != "http" || cfg != nil {
+			t.Errorf("getSchemeAndSecurityConfig(nil, ...) = (%q, %v), want (\"http\", nil)", scheme, cfg)
+		}
+	})
+
+	t.Run("peer SAN skip is gated on version, independent of cipher-suite support", func(t *testing.T) {
+		_, cfg := getSchemeAndSecurityConfig(tlsConfig, caPath, serverTLSPath, "3.2.99", true)
+		if cfg.PeerSkipClientSANVerification {
+			t.Errorf("expected PeerSkipClientSANVerification to stay unset against etcd 3.2.99, got true")
+		}
+
+		_, cfg = getSchemeAndSecurityConfig(tlsConfig, caPath, serverTLSPath, "3.3.0", true)
+		if !cfg.PeerSkipClientSANVerification {
+			t.Errorf("expected PeerSkipClientSANVerification to be set against etcd 3.3.0, got false")
+		}
+	})
+
+	t.Run("peer SAN skip is never set for the client security config", func(t *testing.T) {
+		_, cfg := getSchemeAndSecurityConfig(tlsConfig, caPath, serverTLSPath, "3.5.9", false)
+		if cfg.PeerSkipClientSANVerification {
+			t.Errorf("expected PeerSkipClientSANVerification to stay unset on the client security config, got true")
+		}
+	})
+
+	t.Run("cipher-suite flags are gated on version", func(t *testing.T) {
+		scheme, cfg := getSchemeAndSecurityConfig(tlsConfig, caPath, serverTLSPath, "3.3.9", true)
+		if scheme != "https" {
+			t.Errorf("scheme = %q, want %q", scheme, "https")
+		}
+		if cfg.TLSMinVersion != "" || cfg.CipherSuites != "" {
+			t.Errorf("expected TLSMinVersion/CipherSuites to stay unset against etcd 3.3.9, got %q / %q", cfg.TLSMinVersion, cfg.CipherSuites)
+		}
+
+		_, cfg = getSchemeAndSecurityConfig(tlsConfig, caPath, serverTLSPath, "3.4.0", true)
+		if cfg.TLSMinVersion != "TLS1.2" {
+			t.Errorf("TLSMinVersion = %q, want %q", cfg.TLSMinVersion, "TLS1.2")
+		}
+		if cfg.CipherSuites != "TLS_AES_128_GCM_SHA256" {
+			t.Errorf("CipherSuites = %q, want %q", cfg.CipherSuites, "TLS_AES_128_GCM_SHA256")
+		}
+	})
+}
+
+func boolPtr(v bool) *bool       { return &v }
+func stringPtr(v string) *string { return &v }
+
+func TestGetTuningDefaults(t *testing.T) {
+	etcd := &druidv1alpha1.Etcd{}
+
+	if got := getHeartbeatIntervalMs(etcd); got != defaultHeartbeatIntervalMs {
+		t.Errorf("getHeartbeatIntervalMs() = %d, want %d", got, defaultHeartbeatIntervalMs)
+	}
+	if got := getElectionTimeoutMs(etcd); got != defaultElectionTimeoutMs {
+		t.Errorf("getElectionTimeoutMs() = %d, want %d", got, defaultElectionTimeoutMs)
+	}
+	if got := getSnapshotCount(etcd); got != defaultSnapshotCount {
+		t.Errorf("getSnapshotCount() = %d, want %d", got, defaultSnapshotCount)
+	}
+	if got := getMaxRequestBytes(etcd); got != uint(defaultMaxRequestBytes) {
+		t.Errorf("getMaxRequestBytes() = %d, want %d", got, uint(defaultMaxRequestBytes))
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package statefulset computes the pieces of StatefulSet state the etcd
+// controller needs in order to roll out a changed etcd config and report
+// when that rollout has finished.
+package statefulset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// ConfigChecksumAnnotation is set on the StatefulSet's pod template,
+// keyed to a hash of the rendered etcd config. Changing the annotation value
+// is what actually forces a StatefulSet rolling update when the config
+// changes; the ConfigMap's own content is not part of the pod template and
+// so would otherwise not trigger a rollout on its own.
+const ConfigChecksumAnnotation = "checksum/etcd-config"
+
+// PodTemplateAnnotations returns the annotations that must be merged into
+// the StatefulSet's pod template so that a change to the rendered etcd
+// config (configData, keyed by ConfigMap data key, e.g. by pod name for
+// per-pod configs) triggers a rolling update. The checksum covers every
+// entry of configData and sits on the pod template shared by all pods, so a
+// change to any one member's config rolls every pod.
+func PodTemplateAnnotations(configData map[string]string) map[string]string {
+	return map[string]string{
+		ConfigChecksumAnnotation: configChecksum(configData),
+	}
+}
+
+// configChecksum hashes configData deterministically, independent of map
+// iteration order, so the same rendered config always produces the same
+// checksum annotation.
+func configChecksum(configData map[string]string) string {
+	keys := make([]string, 0, len(configData))
+	for k := range configData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(configData[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ObservedGeneration computes the value the etcd controller should write to
+// Etcd.Status.ObservedGeneration for the given, already-reconciled
+// StatefulSet. It advances to etcd.Generation once sts confirms the rollout
+// triggered by that generation has actually finished (every replica updated
+// to the StatefulSet's current revision and ready); until then it returns
+// the previous value unchanged, so ConfigAppliedCheck keeps reporting
+// "ConfigRolloutInProgress"/False instead of prematurely flipping to True.
+func ObservedGeneration(etcd *druidv1alpha1.Etcd, sts *appsv1.StatefulSet) *int64 {
+	if sts == nil || sts.Status.ObservedGeneration < sts.Generation {
+		return etcd.Status.ObservedGeneration
+	}
+	if sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+		return etcd.Status.ObservedGeneration
+	}
+	if sts.Spec.Replicas != nil && sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return etcd.Status.ObservedGeneration
+	}
+
+	observed := etcd.Generation
+	return &observed
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statefulset
+
+import (
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func replicasPtr(v int32) *int32 { return &v }
+
+func TestPodTemplateAnnotationsIsOrderIndependent(t *testing.T) {
+	a := PodTemplateAnnotations(map[string]string{"etcd-main-0": "foo", "etcd-main-1": "bar"})
+	b := PodTemplateAnnotations(map[string]string{"etcd-main-1": "bar", "etcd-main-0": "foo"})
+
+	if a[ConfigChecksumAnnotation] != b[ConfigChecksumAnnotation] {
+		t.Errorf("checksum depends on map iteration order: %q != %q", a[ConfigChecksumAnnotation], b[ConfigChecksumAnnotation])
+	}
+}
+
+func TestPodTemplateAnnotationsChangesWithConfig(t *testing.T) {
+	a := PodTemplateAnnotations(map[string]string{"etcd-main-0": "foo"})
+	b := PodTemplateAnnotations(map[string]string{"etcd-main-0": "bar"})
+
+	if a[ConfigChecksumAnnotation] == b[ConfigChecksumAnnotation] {
+		t.Errorf("expected distinct rendered configs to produce distinct checksums, got %q for both", a[ConfigChecksumAnnotation])
+	}
+}
+
+func TestObservedGeneration(t *testing.T) {
+	previous := int64Ptr(1)
+
+	tests := map[string]struct {
+		etcd *druidv1alpha1.Etcd
+		sts  *appsv1.StatefulSet
+		want *int64
+	}{
+		"nil statefulset leaves the previous value untouched": {
+			etcd: &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: druidv1alpha1.EtcdStatus{ObservedGeneration: previous}},
+			sts:  nil,
+			want: previous,
+		},
+		"statefulset has not yet observed the latest generation": {
+			etcd: &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: druidv1alpha1.EtcdStatus{ObservedGeneration: previous}},
+			sts: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1},
+			},
+			want: previous,
+		},
+		"rollout still updating replicas to the new revision": {
+			etcd: &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: druidv1alpha1.EtcdStatus{ObservedGeneration: previous}},
+			sts: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: replicasPtr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ObservedGeneration: 2,
+					CurrentRevision:    "etcd-main-5647",
+					UpdateRevision:     "etcd-main-6789",
+					ReadyReplicas:      3,
+				},
+			},
+			want: previous,
+		},
+		"rollout finished updating revisions but not every replica is ready yet": {
+			etcd: &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: druidv1alpha1.EtcdStatus{ObservedGeneration: previous}},
+			sts: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: replicasPtr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ObservedGeneration: 2,
+					CurrentRevision:    "etcd-main-6789",
+					UpdateRevision:     "etcd-main-6789",
+					ReadyReplicas:      2,
+				},
+			},
+			want: previous,
+		},
+		"rollout fully complete advances to the current generation": {
+			etcd: &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: druidv1alpha1.EtcdStatus{ObservedGeneration: previous}},
+			sts: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: replicasPtr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ObservedGeneration: 2,
+					CurrentRevision:    "etcd-main-6789",
+					UpdateRevision:     "etcd-main-6789",
+					ReadyReplicas:      3,
+				},
+			},
+			want: int64Ptr(2),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ObservedGeneration(tc.etcd, tc.sts)
+			if (got == nil) != (tc.want == nil) || (got != nil && *got != *tc.want) {
+				t.Errorf("ObservedGeneration() = %v, want %v", derefOrNil(got), derefOrNil(tc.want))
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func derefOrNil(p *int64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
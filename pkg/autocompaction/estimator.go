@@ -0,0 +1,72 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autocompaction computes a revision-based auto-compaction retention
+// value for the AdaptiveAutoCompaction feature gate, from the write rate
+// etcd itself reports.
+package autocompaction
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// minRetention is the lowest revision retention the estimator will ever
+	// hand out, so that a momentarily idle cluster doesn't end up compacting
+	// on every single revision.
+	minRetention = 1000
+	// maxRetention caps retention for write-heavy clusters so that compaction
+	// still runs often enough to bound WAL/DB growth.
+	maxRetention = 1000000
+	// targetCompactionWindow is the time span of history adaptive retention
+	// aims to keep around, regardless of write rate.
+	targetCompactionWindow = 30 * time.Minute
+)
+
+// WriteRateSample is a single observation of etcd's revisions/minute,
+// typically derived from the rate of `etcd_server_proposals_committed_total`
+// (see PrometheusWriteRateSampler), averaged over the scrape window.
+type WriteRateSample struct {
+	// RevisionsPerMinute is the observed write rate at the time of sampling.
+	RevisionsPerMinute float64
+	// ObservedAt is when the sample was taken.
+	ObservedAt time.Time
+}
+
+// EstimateRetention computes a target revision retention from a window of
+// write-rate samples, aiming to retain roughly targetCompactionWindow worth
+// of history: low-traffic clusters get a small retention (compacting more
+// aggressively relative to their revision count), write-heavy clusters get a
+// larger one, clamped to [minRetention, maxRetention].
+func EstimateRetention(samples []WriteRateSample) (int64, error) {
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("cannot estimate retention from zero write-rate samples")
+	}
+
+	var total float64
+	for _, s := range samples {
+		total += s.RevisionsPerMinute
+	}
+	avgPerMinute := total / float64(len(samples))
+
+	retention := int64(avgPerMinute * targetCompactionWindow.Minutes())
+	if retention < minRetention {
+		retention = minRetention
+	}
+	if retention > maxRetention {
+		retention = maxRetention
+	}
+	return retention, nil
+}
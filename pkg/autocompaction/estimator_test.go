@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autocompaction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateRetention(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	tests := map[string]struct {
+		samples       []WriteRateSample
+		wantRetention int64
+		wantErr       bool
+	}{
+		"no samples": {
+			wantErr: true,
+		},
+		"idle cluster clamps to minRetention": {
+			samples: []WriteRateSample{
+				{RevisionsPerMinute: 0, ObservedAt: now},
+			},
+			wantRetention: minRetention,
+		},
+		"write-heavy cluster clamps to maxRetention": {
+			samples: []WriteRateSample{
+				{RevisionsPerMinute: 1_000_000, ObservedAt: now},
+			},
+			wantRetention: maxRetention,
+		},
+		"moderate write rate scales with targetCompactionWindow": {
+			samples: []WriteRateSample{
+				{RevisionsPerMinute: 100, ObservedAt: now},
+				{RevisionsPerMinute: 200, ObservedAt: now.Add(time.Minute)},
+			},
+			wantRetention: int64(150 * targetCompactionWindow.Minutes()),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := EstimateRetention(tc.samples)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tc.wantRetention {
+				t.Errorf("EstimateRetention() = %d, want %d", got, tc.wantRetention)
+			}
+		})
+	}
+}
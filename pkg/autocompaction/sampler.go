@@ -0,0 +1,147 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autocompaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+)
+
+// defaultSampleWindow is how far back PrometheusWriteRateSampler looks for
+// write-rate observations on every Sample call.
+const defaultSampleWindow = 30 * time.Minute
+
+// defaultSampleStep is the resolution PrometheusWriteRateSampler requests
+// its range query at.
+const defaultSampleStep = time.Minute
+
+// writeRateLookback is the rate() lookback window the query itself uses at
+// each evaluated step; it is independent of Window/Step, which control how
+// far back and how often that per-step rate is sampled.
+const writeRateLookback = "5m"
+
+// writeRateQuery computes the cluster-wide write rate, in revisions per
+// minute, from the per-second rate of committed raft proposals summed across
+// every member of the given Etcd cluster.
+const writeRateQuery = `sum(rate(etcd_server_proposals_committed_total{etcd_cluster=%q}[%s])) * 60`
+
+// PrometheusWriteRateSampler implements WriteRateSampler by range-querying a
+// Prometheus server that scrapes the cluster's own etcd_server_* metrics.
+type PrometheusWriteRateSampler struct {
+	// BaseURL is the Prometheus server's base URL, e.g.
+	// "http://prometheus-monitoring.garden:9090".
+	BaseURL string
+	// HTTPClient is used to issue the range query. Defaults to
+	// http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// Window is how far back to sample. Defaults to defaultSampleWindow.
+	Window time.Duration
+	// Step is the resolution to sample at. Defaults to defaultSampleStep.
+	Step time.Duration
+}
+
+// prometheusRangeResponse is the subset of Prometheus's
+// `/api/v1/query_range` response this sampler needs.
+type prometheusRangeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Values [][2]interface{} `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Sample queries Prometheus for the cluster's write rate over the last
+// Window, at Step resolution.
+func (s *PrometheusWriteRateSampler) Sample(ctx context.Context, etcd druidv1alpha1.Etcd) ([]WriteRateSample, error) {
+	window := s.Window
+	if window <= 0 {
+		window = defaultSampleWindow
+	}
+	step := s.Step
+	if step <= 0 {
+		step = defaultSampleStep
+	}
+
+	now := time.Now()
+	query := fmt.Sprintf(writeRateQuery, etcd.Name, writeRateLookback)
+
+	reqURL := fmt.Sprintf("%s/api/v1/query_range?%s", s.BaseURL, url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(now.Add(-window).Unix(), 10)},
+		"end":   {strconv.FormatInt(now.Unix(), 10)},
+		"step":  {step.String()},
+	}.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building Prometheus range query request: %w", err)
+	}
+
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying Prometheus for etcd %s/%s write rate: %w", etcd.Namespace, etcd.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var parsed prometheusRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decoding Prometheus range query response: %w", err)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("Prometheus range query for etcd %s/%s failed: %s", etcd.Namespace, etcd.Name, parsed.Error)
+	}
+	if len(parsed.Data.Result) == 0 {
+		return nil, fmt.Errorf("Prometheus has no etcd_server_proposals_committed_total samples for etcd %s/%s yet", etcd.Namespace, etcd.Name)
+	}
+
+	samples := make([]WriteRateSample, 0, len(parsed.Data.Result[0].Values))
+	for _, v := range parsed.Data.Result[0].Values {
+		ts, ok := v[0].(float64)
+		if !ok {
+			continue
+		}
+		valueStr, ok := v[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, WriteRateSample{
+			RevisionsPerMinute: value,
+			ObservedAt:         time.Unix(int64(ts), 0),
+		})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("Prometheus returned no parseable write-rate samples for etcd %s/%s", etcd.Namespace, etcd.Name)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autocompaction
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	"github.com/gardener/etcd-druid/pkg/features"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AnnotationAdaptiveCompactionRetention is where the updater records the
+// retention value it last estimated. The configmap component reads it back
+// to render `auto-compaction-retention` once the AdaptiveAutoCompaction
+// feature gate is enabled.
+const AnnotationAdaptiveCompactionRetention = "compaction.druid.gardener.cloud/adaptive-retention"
+
+// WriteRateSampler returns a window of recent write-rate observations for the
+// given Etcd cluster. PrometheusWriteRateSampler implements it by querying a
+// Prometheus server; the interface keeps the estimation logic independent of
+// any particular metrics client.
+type WriteRateSampler interface {
+	Sample(ctx context.Context, etcd druidv1alpha1.Etcd) ([]WriteRateSample, error)
+}
+
+// Updater periodically estimates a revision retention value for clusters
+// running with AdaptiveAutoCompaction and patches it onto the Etcd resource,
+// so that the configmap component can pick it up on the next reconcile.
+type Updater struct {
+	Client  client.Client
+	Sampler WriteRateSampler
+}
+
+// UpdateRetention samples the cluster's current write rate, estimates a new
+// retention value, and patches it onto the Etcd resource's annotations if it
+// changed. It is meant to be invoked periodically (e.g. from a ticker-driven
+// controller loop) for every Etcd that has AdaptiveAutoCompaction enabled.
+func (u *Updater) UpdateRetention(ctx context.Context, etcd *druidv1alpha1.Etcd) error {
+	samples, err := u.Sampler.Sample(ctx, *etcd)
+	if err != nil {
+		return fmt.Errorf("sampling write rate for etcd %s/%s: %w", etcd.Namespace, etcd.Name, err)
+	}
+
+	retention, err := EstimateRetention(samples)
+	if err != nil {
+		return fmt.Errorf("estimating retention for etcd %s/%s: %w", etcd.Namespace, etcd.Name, err)
+	}
+
+	retentionStr := strconv.FormatInt(retention, 10)
+	if etcd.Annotations[AnnotationAdaptiveCompactionRetention] == retentionStr {
+		return nil
+	}
+
+	patch := client.MergeFrom(etcd.DeepCopy())
+	if etcd.Annotations == nil {
+		etcd.Annotations = map[string]string{}
+	}
+	etcd.Annotations[AnnotationAdaptiveCompactionRetention] = retentionStr
+	return u.Client.Patch(ctx, etcd, patch)
+}
+
+// Run drives UpdateRetention on a fixed interval for every Etcd cluster
+// listEtcds returns that has AdaptiveAutoCompaction enabled, until ctx is
+// done. Per-cluster errors are reported to onError and do not stop the loop
+// from moving on to the next cluster or the next tick.
+func (u *Updater) Run(ctx context.Context, interval time.Duration, listEtcds func(ctx context.Context) ([]druidv1alpha1.Etcd, error), onError func(etcd druidv1alpha1.Etcd, err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			etcds, err := listEtcds(ctx)
+			if err != nil {
+				if onError != nil {
+					onError(druidv1alpha1.Etcd{}, fmt.Errorf("listing Etcd clusters: %w", err))
+				}
+				continue
+			}
+			for i := range etcds {
+				if !features.FeatureGate.Enabled(features.AdaptiveAutoCompaction) {
+					continue
+				}
+				if err := u.UpdateRetention(ctx, &etcds[i]); err != nil && onError != nil {
+					onError(etcds[i], err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autocompaction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// patchRecordingClient wraps a nil client.Client, overriding only Patch, so
+// tests can assert on what Updater tries to persist without standing up a
+// fake API server or registering a scheme.
+type patchRecordingClient struct {
+	client.Client
+	patchedAnnotations map[string]string
+}
+
+func (p *patchRecordingClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	etcd, ok := obj.(*druidv1alpha1.Etcd)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+	p.patchedAnnotations = etcd.Annotations
+	return nil
+}
+
+type stubSampler struct {
+	samples []WriteRateSample
+	err     error
+}
+
+func (s *stubSampler) Sample(_ context.Context, _ druidv1alpha1.Etcd) ([]WriteRateSample, error) {
+	return s.samples, s.err
+}
+
+func TestUpdateRetention(t *testing.T) {
+	t.Run("patches the estimated retention onto a cluster with no prior annotation", func(t *testing.T) {
+		recorder := &patchRecordingClient{}
+		u := &Updater{
+			Client:  recorder,
+			Sampler: &stubSampler{samples: []WriteRateSample{{RevisionsPerMinute: 1000, ObservedAt: time.Now()}}},
+		}
+		etcd := &druidv1alpha1.Etcd{}
+
+		if err := u.UpdateRetention(context.Background(), etcd); err != nil {
+			t.Fatalf("UpdateRetention() returned an unexpected error: %v", err)
+		}
+
+		want, err := EstimateRetention([]WriteRateSample{{RevisionsPerMinute: 1000}})
+		if err != nil {
+			t.Fatalf("EstimateRetention() returned an unexpected error: %v", err)
+		}
+		if got := recorder.patchedAnnotations[AnnotationAdaptiveCompactionRetention]; got != fmt.Sprint(want) {
+			t.Errorf("patched annotation = %q, want %q", got, fmt.Sprint(want))
+		}
+	})
+
+	t.Run("does not patch when the estimated retention is unchanged", func(t *testing.T) {
+		recorder := &patchRecordingClient{}
+		u := &Updater{
+			Client:  recorder,
+			Sampler: &stubSampler{samples: []WriteRateSample{{RevisionsPerMinute: 1000}}},
+		}
+		want, err := EstimateRetention([]WriteRateSample{{RevisionsPerMinute: 1000}})
+		if err != nil {
+			t.Fatalf("EstimateRetention() returned an unexpected error: %v", err)
+		}
+		etcd := &druidv1alpha1.Etcd{}
+		etcd.Annotations = map[string]string{AnnotationAdaptiveCompactionRetention: fmt.Sprint(want)}
+
+		if err := u.UpdateRetention(context.Background(), etcd); err != nil {
+			t.Fatalf("UpdateRetention() returned an unexpected error: %v", err)
+		}
+		if recorder.patchedAnnotations != nil {
+			t.Errorf("expected no Patch call when the retention value is unchanged, got annotations %v", recorder.patchedAnnotations)
+		}
+	})
+
+	t.Run("propagates a sampling error without patching", func(t *testing.T) {
+		recorder := &patchRecordingClient{}
+		u := &Updater{
+			Client:  recorder,
+			Sampler: &stubSampler{err: fmt.Errorf("scrape failed")},
+		}
+
+		if err := u.UpdateRetention(context.Background(), &druidv1alpha1.Etcd{}); err == nil {
+			t.Errorf("expected an error when the sampler fails, got nil")
+		}
+		if recorder.patchedAnnotations != nil {
+			t.Errorf("expected no Patch call when sampling fails, got annotations %v", recorder.patchedAnnotations)
+		}
+	})
+}
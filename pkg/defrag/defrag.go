@@ -0,0 +1,182 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package defrag drives the rolling, leader-last member defragmentation that
+// the etcd controller performs once an Etcd resource is annotated with
+// condition.OperationAnnotationDefragment, and clears any NOSPACE alarm the
+// freed-up space resolves once defragmentation succeeds.
+package defrag
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	"github.com/gardener/etcd-druid/pkg/health/condition"
+
+	etcdserverpb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// defaultMemberHealthTimeout bounds how long Run waits for a member to
+	// respond again after being defragmented before giving up on the
+	// remaining rolling-defrag steps.
+	defaultMemberHealthTimeout = 2 * time.Minute
+	healthPollInterval         = 2 * time.Second
+)
+
+// EtcdClientBuilder creates an etcd client for the given cluster. It mirrors
+// the client builder used by pkg/health/condition, so the same
+// implementation (see condition.NewClientBuilder) can be reused here.
+type EtcdClientBuilder interface {
+	NewClient(ctx context.Context, etcd druidv1alpha1.Etcd) (*clientv3.Client, error)
+}
+
+// etcdMaintenanceClient is the subset of *clientv3.Client that the
+// leader-last rolling defrag actually drives. Narrowing it down to these five
+// methods lets tests exercise the ordering logic and its edge cases against a
+// fake, without a live etcd server; *clientv3.Client satisfies this interface
+// as-is.
+type etcdMaintenanceClient interface {
+	MemberList(ctx context.Context, opts ...clientv3.OpOption) (*clientv3.MemberListResponse, error)
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+	MoveLeader(ctx context.Context, transfereeID uint64) (*clientv3.MoveLeaderResponse, error)
+	Defragment(ctx context.Context, endpoint string, opts ...clientv3.OpOption) (*clientv3.DefragmentResponse, error)
+	Endpoints() []string
+}
+
+// ShouldDefragment reports whether etcd currently carries the operation
+// annotation requesting a rolling defragmentation.
+func ShouldDefragment(etcd *druidv1alpha1.Etcd) bool {
+	return etcd.Annotations[condition.OperationAnnotation] == condition.OperationAnnotationDefragment
+}
+
+// MaybeRun runs a rolling defragmentation of etcd if and only if
+// ShouldDefragment reports that the operation annotation requests one. It can
+// be called on every reconcile: without the annotation it returns nil and
+// does nothing.
+func MaybeRun(ctx context.Context, clientBuilder EtcdClientBuilder, etcd druidv1alpha1.Etcd) error {
+	if !ShouldDefragment(&etcd) {
+		return nil
+	}
+	return Run(ctx, clientBuilder, etcd)
+}
+
+// Run defragments every member of the cluster one at a time, leader last: the
+// leader keeps serving writes for as long as possible, and MoveLeader hands
+// off quorum to an already-defragmented follower right before the leader
+// itself is defragmented, instead of forcing a disruptive election mid
+// maintenance. Between members, Run waits for the just-defragmented member to
+// become reachable again before moving on, so the cluster always has a full
+// quorum of healthy members available for the next step. Once every member
+// has been defragmented, Run clears any active NOSPACE alarm, since the
+// defragmentation it just performed is the remediation that alarm was
+// raised to prompt.
+func Run(ctx context.Context, clientBuilder EtcdClientBuilder, etcd druidv1alpha1.Etcd) error {
+	cli, err := clientBuilder.NewClient(ctx, etcd)
+	if err != nil {
+		return fmt.Errorf("creating etcd client: %w", err)
+	}
+	defer cli.Close()
+
+	if err := run(ctx, cli); err != nil {
+		return err
+	}
+
+	return condition.AutoDisarmNoSpaceAlarms(ctx, clientBuilder, etcd)
+}
+
+// run implements the leader-last rolling defrag against cli, independent of
+// how cli was created, so tests can drive it against a fake
+// etcdMaintenanceClient.
+func run(ctx context.Context, cli etcdMaintenanceClient) error {
+	members, err := cli.MemberList(ctx)
+	if err != nil {
+		return fmt.Errorf("listing members: %w", err)
+	}
+	if len(members.Members) == 0 {
+		return fmt.Errorf("cluster reported zero members")
+	}
+
+	status, err := cli.Status(ctx, cli.Endpoints()[0])
+	if err != nil {
+		return fmt.Errorf("getting cluster status: %w", err)
+	}
+
+	var leader *etcdserverpb.Member
+	followers := make([]*etcdserverpb.Member, 0, len(members.Members))
+	for _, m := range members.Members {
+		if m.ID == status.Leader {
+			leader = m
+			continue
+		}
+		followers = append(followers, m)
+	}
+
+	for _, m := range followers {
+		if err := defragmentMember(ctx, cli, m); err != nil {
+			return err
+		}
+	}
+
+	if leader == nil {
+		// The leader could not be identified among the listed members; every
+		// member has already been defragmented above.
+		return nil
+	}
+
+	if len(followers) > 0 {
+		if _, err := cli.MoveLeader(ctx, followers[0].ID); err != nil {
+			return fmt.Errorf("transferring leadership away from member %x before defragmenting it: %w", leader.ID, err)
+		}
+	}
+
+	return defragmentMember(ctx, cli, leader)
+}
+
+func defragmentMember(ctx context.Context, cli etcdMaintenanceClient, m *etcdserverpb.Member) error {
+	if len(m.ClientURLs) == 0 {
+		return fmt.Errorf("member %x has no client URLs to defragment against", m.ID)
+	}
+	endpoint := m.ClientURLs[0]
+
+	if _, err := cli.Defragment(ctx, endpoint); err != nil {
+		return fmt.Errorf("defragmenting member %x: %w", m.ID, err)
+	}
+
+	return waitUntilHealthy(ctx, cli, endpoint)
+}
+
+// waitUntilHealthy polls the given member's status endpoint until it
+// responds or the context times out.
+func waitUntilHealthy(ctx context.Context, cli etcdMaintenanceClient, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultMemberHealthTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := cli.Status(ctx, endpoint); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("member at %s did not become healthy again after defragmentation: %w", endpoint, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
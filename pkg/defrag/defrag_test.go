@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package defrag
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	"github.com/gardener/etcd-druid/pkg/health/condition"
+
+	etcdserverpb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestShouldDefragment(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        bool
+	}{
+		"no annotations": {},
+		"unrelated annotation": {
+			annotations: map[string]string{"foo": "bar"},
+		},
+		"operation annotation set to defragment": {
+			annotations: map[string]string{condition.OperationAnnotation: condition.OperationAnnotationDefragment},
+			want:        true,
+		},
+		"operation annotation set to something else": {
+			annotations: map[string]string{condition.OperationAnnotation: "reconcile"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			etcd := &druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if got := ShouldDefragment(etcd); got != tc.want {
+				t.Errorf("ShouldDefragment() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// fakeMaintenanceClient is a minimal, in-memory etcdMaintenanceClient used to
+// drive run() without a live etcd server.
+type fakeMaintenanceClient struct {
+	members    []*etcdserverpb.Member
+	leaderID   uint64
+	endpoints  []string
+	defragged  []uint64
+	movedTo    uint64
+	moveCalled bool
+
+	memberListErr error
+	statusErr     error
+	moveLeaderErr error
+	defragmentErr map[uint64]error
+}
+
+func (f *fakeMaintenanceClient) MemberList(context.Context, ...clientv3.OpOption) (*clientv3.MemberListResponse, error) {
+	if f.memberListErr != nil {
+		return nil, f.memberListErr
+	}
+	return &clientv3.MemberListResponse{Members: f.members}, nil
+}
+
+func (f *fakeMaintenanceClient) Status(context.Context, string) (*clientv3.StatusResponse, error) {
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	return &clientv3.StatusResponse{Leader: f.leaderID}, nil
+}
+
+func (f *fakeMaintenanceClient) MoveLeader(_ context.Context, transfereeID uint64) (*clientv3.MoveLeaderResponse, error) {
+	if f.moveLeaderErr != nil {
+		return nil, f.moveLeaderErr
+	}
+	f.moveCalled = true
+	f.movedTo = transfereeID
+	return &clientv3.MoveLeaderResponse{}, nil
+}
+
+func (f *fakeMaintenanceClient) Defragment(_ context.Context, endpoint string, _ ...clientv3.OpOption) (*clientv3.DefragmentResponse, error) {
+	id := endpointMemberID(f.members, endpoint)
+	if err := f.defragmentErr[id]; err != nil {
+		return nil, err
+	}
+	f.defragged = append(f.defragged, id)
+	return &clientv3.DefragmentResponse{}, nil
+}
+
+func (f *fakeMaintenanceClient) Endpoints() []string {
+	return f.endpoints
+}
+
+func endpointMemberID(members []*etcdserverpb.Member, endpoint string) uint64 {
+	for _, m := range members {
+		for _, url := range m.ClientURLs {
+			if url == endpoint {
+				return m.ID
+			}
+		}
+	}
+	return 0
+}
+
+func member(id uint64) *etcdserverpb.Member {
+	return &etcdserverpb.Member{ID: id, ClientURLs: []string{fmt.Sprintf("http://member-%x:2379", id)}}
+}
+
+func TestRunLeaderLastOrdering(t *testing.T) {
+	leader, f1, f2 := member(1), member(2), member(3)
+	cli := &fakeMaintenanceClient{
+		members:   []*etcdserverpb.Member{leader, f1, f2},
+		leaderID:  leader.ID,
+		endpoints: []string{leader.ClientURLs[0]},
+	}
+
+	if err := run(context.Background(), cli); err != nil {
+		t.Fatalf("run() returned an unexpected error: %v", err)
+	}
+
+	if len(cli.defragged) != 3 {
+		t.Fatalf("expected all 3 members to be defragmented, got %d", len(cli.defragged))
+	}
+	if cli.defragged[len(cli.defragged)-1] != leader.ID {
+		t.Errorf("expected the leader to be defragmented last, got order %v", cli.defragged)
+	}
+	if !cli.moveCalled {
+		t.Errorf("expected MoveLeader to be called before defragmenting the leader")
+	}
+	if cli.movedTo != f1.ID {
+		t.Errorf("expected leadership to move to the first already-defragmented follower %x, got %x", f1.ID, cli.movedTo)
+	}
+}
+
+func TestRunSingleMember(t *testing.T) {
+	leader := member(1)
+	cli := &fakeMaintenanceClient{
+		members:   []*etcdserverpb.Member{leader},
+		leaderID:  leader.ID,
+		endpoints: []string{leader.ClientURLs[0]},
+	}
+
+	if err := run(context.Background(), cli); err != nil {
+		t.Fatalf("run() returned an unexpected error: %v", err)
+	}
+	if len(cli.defragged) != 1 || cli.defragged[0] != leader.ID {
+		t.Errorf("expected the single member to be defragmented, got %v", cli.defragged)
+	}
+	if cli.moveCalled {
+		t.Errorf("expected MoveLeader not to be called with no followers to move leadership to")
+	}
+}
+
+func TestRunNoLeaderFound(t *testing.T) {
+	m1, m2 := member(1), member(2)
+	cli := &fakeMaintenanceClient{
+		members:   []*etcdserverpb.Member{m1, m2},
+		leaderID:  999, // does not match any listed member
+		endpoints: []string{m1.ClientURLs[0]},
+	}
+
+	if err := run(context.Background(), cli); err != nil {
+		t.Fatalf("run() returned an unexpected error: %v", err)
+	}
+	if len(cli.defragged) != 2 {
+		t.Errorf("expected both members to be defragmented as followers, got %v", cli.defragged)
+	}
+	if cli.moveCalled {
+		t.Errorf("expected MoveLeader not to be called when no leader could be identified")
+	}
+}
+
+func TestRunZeroMembers(t *testing.T) {
+	cli := &fakeMaintenanceClient{endpoints: []string{"http://member-1:2379"}}
+
+	if err := run(context.Background(), cli); err == nil {
+		t.Errorf("expected an error when the cluster reports zero members, got nil")
+	}
+}
@@ -31,13 +31,22 @@ const (
 	// owner @abdasgupta, @timuthy
 	// alpha: v0.7.0
 	BackupCompaction featuregate.Feature = "BackupCompaction"
+
+	// AdaptiveAutoCompaction switches etcd's auto-compaction-mode from the
+	// fixed "periodic" default to "revision", with the retention value picked
+	// dynamically from the cluster's observed write rate instead of a static
+	// setting.
+	// owner @etcd-druid-maintainers
+	// alpha: v0.21.0
+	AdaptiveAutoCompaction featuregate.Feature = "AdaptiveAutoCompaction"
 )
 
 var (
 	// FeatureGate is a shared global FeatureGate for Etcd-Druid flags.
 	FeatureGate  = featuregate.NewFeatureGate()
 	featureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		BackupCompaction: {Default: false, PreRelease: featuregate.Alpha},
+		BackupCompaction:       {Default: false, PreRelease: featuregate.Alpha},
+		AdaptiveAutoCompaction: {Default: false, PreRelease: featuregate.Alpha},
 	}
 )
 
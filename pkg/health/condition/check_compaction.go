@@ -0,0 +1,111 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"fmt"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperationAnnotation, when set to OperationAnnotationDefragment, tells the
+// etcd controller to drive a rolling, leader-last defragmentation of the
+// cluster instead of waiting for the next scheduled compaction.
+const (
+	OperationAnnotation           = "druid.gardener.cloud/operation"
+	OperationAnnotationDefragment = "defragment"
+)
+
+// labelSelectorForCompactionJobs selects every snapshot-compactor Job created
+// for the given Etcd by the BackupCompaction feature; Check then picks the
+// most recent one among them.
+func labelSelectorForCompactionJobs(etcd druidv1alpha1.Etcd) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		"app.kubernetes.io/component": "compaction-job",
+		"app.kubernetes.io/part-of":   etcd.Name,
+	})
+}
+
+type compactionSucceeded struct {
+	client client.Client
+}
+
+func (c *compactionSucceeded) Check(ctx context.Context, etcd druidv1alpha1.Etcd) Result {
+	jobList := &batchv1.JobList{}
+	if err := c.client.List(ctx, jobList, client.InNamespace(etcd.Namespace), client.MatchingLabelsSelector{Selector: labelSelectorForCompactionJobs(etcd)}); err != nil {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeCompactionSucceeded,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "CompactionJobListFailed",
+			message: fmt.Sprintf("Could not list snapshot-compactor jobs: %v", err),
+		}
+	}
+
+	if len(jobList.Items) == 0 {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeCompactionSucceeded,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "NoCompactionJobFound",
+			message: "No snapshot-compactor job has run yet",
+		}
+	}
+
+	latest := jobList.Items[0]
+	for _, job := range jobList.Items[1:] {
+		if job.CreationTimestamp.After(latest.CreationTimestamp.Time) {
+			latest = job
+		}
+	}
+
+	switch {
+	case latest.Status.Succeeded > 0:
+		duration := "unknown"
+		if latest.Status.StartTime != nil && latest.Status.CompletionTime != nil {
+			duration = latest.Status.CompletionTime.Sub(latest.Status.StartTime.Time).String()
+		}
+		return &result{
+			conType: druidv1alpha1.ConditionTypeCompactionSucceeded,
+			status:  druidv1alpha1.ConditionTrue,
+			reason:  "CompactionJobSucceeded",
+			message: fmt.Sprintf("Snapshot compaction job %q completed in %s", latest.Name, duration),
+		}
+	case latest.Status.Failed > 0:
+		return &result{
+			conType: druidv1alpha1.ConditionTypeCompactionSucceeded,
+			status:  druidv1alpha1.ConditionFalse,
+			reason:  "CompactionJobFailed",
+			message: fmt.Sprintf("Snapshot compaction job %q failed", latest.Name),
+		}
+	default:
+		return &result{
+			conType: druidv1alpha1.ConditionTypeCompactionSucceeded,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "CompactionJobRunning",
+			message: fmt.Sprintf("Snapshot compaction job %q is still running", latest.Name),
+		}
+	}
+}
+
+// CompactionSucceededCheck returns a check for the "CompactionSucceeded"
+// condition, which reflects the outcome of the most recent snapshot-compactor
+// job run by the BackupCompaction feature.
+func CompactionSucceededCheck(c client.Client) Checker {
+	return &compactionSucceeded{client: c}
+}
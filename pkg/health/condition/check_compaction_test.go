@@ -0,0 +1,102 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func compactionJobLabels(etcdName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/component": "compaction-job",
+		"app.kubernetes.io/part-of":   etcdName,
+	}
+}
+
+func TestCompactionSucceededCheck(t *testing.T) {
+	etcd := druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Name: "etcd-main", Namespace: "default"}}
+
+	t.Run("no compaction job found", func(t *testing.T) {
+		c := fakeclient.NewClientBuilder().Build()
+		got, ok := CompactionSucceededCheck(c).Check(context.Background(), etcd).(*result)
+		if !ok {
+			t.Fatalf("Check() did not return a *result")
+		}
+		if got.status != druidv1alpha1.ConditionUnknown || got.reason != "NoCompactionJobFound" {
+			t.Errorf("status/reason = %q/%q, want %q/%q", got.status, got.reason, druidv1alpha1.ConditionUnknown, "NoCompactionJobFound")
+		}
+	})
+
+	t.Run("most recent job succeeded", func(t *testing.T) {
+		start := metav1.NewTime(time.Now().Add(-time.Minute))
+		end := metav1.NewTime(time.Now())
+		older := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "compact-1", Namespace: "default", Labels: compactionJobLabels("etcd-main"), CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+			Status:     batchv1.JobStatus{Failed: 1},
+		}
+		newer := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "compact-2", Namespace: "default", Labels: compactionJobLabels("etcd-main"), CreationTimestamp: metav1.NewTime(time.Now())},
+			Status:     batchv1.JobStatus{Succeeded: 1, StartTime: &start, CompletionTime: &end},
+		}
+		c := fakeclient.NewClientBuilder().WithObjects(older, newer).Build()
+
+		got, ok := CompactionSucceededCheck(c).Check(context.Background(), etcd).(*result)
+		if !ok {
+			t.Fatalf("Check() did not return a *result")
+		}
+		if got.status != druidv1alpha1.ConditionTrue || got.reason != "CompactionJobSucceeded" {
+			t.Errorf("status/reason = %q/%q, want %q/%q", got.status, got.reason, druidv1alpha1.ConditionTrue, "CompactionJobSucceeded")
+		}
+	})
+
+	t.Run("most recent job failed", func(t *testing.T) {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "compact-1", Namespace: "default", Labels: compactionJobLabels("etcd-main"), CreationTimestamp: metav1.Now()},
+			Status:     batchv1.JobStatus{Failed: 1},
+		}
+		c := fakeclient.NewClientBuilder().WithObjects(job).Build()
+
+		got, ok := CompactionSucceededCheck(c).Check(context.Background(), etcd).(*result)
+		if !ok {
+			t.Fatalf("Check() did not return a *result")
+		}
+		if got.status != druidv1alpha1.ConditionFalse || got.reason != "CompactionJobFailed" {
+			t.Errorf("status/reason = %q/%q, want %q/%q", got.status, got.reason, druidv1alpha1.ConditionFalse, "CompactionJobFailed")
+		}
+	})
+
+	t.Run("most recent job still running", func(t *testing.T) {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "compact-1", Namespace: "default", Labels: compactionJobLabels("etcd-main"), CreationTimestamp: metav1.Now()},
+		}
+		c := fakeclient.NewClientBuilder().WithObjects(job).Build()
+
+		got, ok := CompactionSucceededCheck(c).Check(context.Background(), etcd).(*result)
+		if !ok {
+			t.Fatalf("Check() did not return a *result")
+		}
+		if got.status != druidv1alpha1.ConditionUnknown || got.reason != "CompactionJobRunning" {
+			t.Errorf("status/reason = %q/%q, want %q/%q", got.status, got.reason, druidv1alpha1.ConditionUnknown, "CompactionJobRunning")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+)
+
+type configApplied struct{}
+
+func (c *configApplied) Check(_ context.Context, etcd druidv1alpha1.Etcd) Result {
+	if etcd.Status.ObservedGeneration == nil {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeConfigApplied,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "NoObservedGeneration",
+			message: "Configuration has not been reconciled yet",
+		}
+	}
+
+	if *etcd.Status.ObservedGeneration < etcd.Generation {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeConfigApplied,
+			status:  druidv1alpha1.ConditionFalse,
+			reason:  "ConfigRolloutInProgress",
+			message: "The etcd configuration rendered from the current spec is still being rolled out to all members",
+		}
+	}
+
+	return &result{
+		conType: druidv1alpha1.ConditionTypeConfigApplied,
+		status:  druidv1alpha1.ConditionTrue,
+		reason:  "ConfigApplied",
+		message: "The etcd configuration rendered from the current spec has been applied to all members",
+	}
+}
+
+// ConfigAppliedCheck returns a check for the "ConfigApplied" condition, which
+// reports True once every member is running with the configuration rendered
+// from the current, observed Etcd.Generation.
+func ConfigAppliedCheck() Checker {
+	return &configApplied{}
+}
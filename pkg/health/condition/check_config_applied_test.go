@@ -0,0 +1,75 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestConfigAppliedCheck(t *testing.T) {
+	tests := map[string]struct {
+		etcd       druidv1alpha1.Etcd
+		wantStatus druidv1alpha1.ConditionStatus
+		wantReason string
+	}{
+		"no observed generation yet": {
+			etcd:       druidv1alpha1.Etcd{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			wantStatus: druidv1alpha1.ConditionUnknown,
+			wantReason: "NoObservedGeneration",
+		},
+		"observed generation behind the current spec": {
+			etcd: druidv1alpha1.Etcd{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     druidv1alpha1.EtcdStatus{ObservedGeneration: int64Ptr(1)},
+			},
+			wantStatus: druidv1alpha1.ConditionFalse,
+			wantReason: "ConfigRolloutInProgress",
+		},
+		"observed generation caught up to the current spec": {
+			etcd: druidv1alpha1.Etcd{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     druidv1alpha1.EtcdStatus{ObservedGeneration: int64Ptr(2)},
+			},
+			wantStatus: druidv1alpha1.ConditionTrue,
+			wantReason: "ConfigApplied",
+		},
+	}
+
+	check := ConfigAppliedCheck()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := check.Check(context.Background(), tc.etcd).(*result)
+			if !ok {
+				t.Fatalf("Check() did not return a *result")
+			}
+			if got.conType != druidv1alpha1.ConditionTypeConfigApplied {
+				t.Errorf("conType = %q, want %q", got.conType, druidv1alpha1.ConditionTypeConfigApplied)
+			}
+			if got.status != tc.wantStatus {
+				t.Errorf("status = %q, want %q", got.status, tc.wantStatus)
+			}
+			if got.reason != tc.wantReason {
+				t.Errorf("reason = %q, want %q", got.reason, tc.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdClientBuilder creates an etcd client talking to the given Etcd
+// cluster's client endpoints. It is satisfied by clientBuilder (see
+// NewClientBuilder), which trusts the CA referenced by the cluster's
+// ClientUrlTLS but presents no client certificate of its own.
+type etcdClientBuilder interface {
+	NewClient(ctx context.Context, etcd druidv1alpha1.Etcd) (*clientv3.Client, error)
+}
+
+type noAlarms struct {
+	clientBuilder etcdClientBuilder
+}
+
+func (n *noAlarms) Check(ctx context.Context, etcd druidv1alpha1.Etcd) Result {
+	cli, err := n.clientBuilder.NewClient(ctx, etcd)
+	if err != nil {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeNoAlarms,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "EtcdClientCreationFailed",
+			message: fmt.Sprintf("Could not create etcd client to check alarms: %v", err),
+		}
+	}
+	defer cli.Close()
+
+	resp, err := cli.AlarmList(ctx)
+	if err != nil {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeNoAlarms,
+			status:  druidv1alpha1.ConditionUnknown,
+			reason:  "AlarmListFailed",
+			message: fmt.Sprintf("Could not list etcd alarms: %v", err),
+		}
+	}
+
+	if len(resp.Alarms) == 0 {
+		return &result{
+			conType: druidv1alpha1.ConditionTypeNoAlarms,
+			status:  druidv1alpha1.ConditionTrue,
+			reason:  "NoAlarmsRaised",
+			message: "No alarms are active on any member",
+		}
+	}
+
+	messages := make([]string, 0, len(resp.Alarms))
+	for _, alarm := range resp.Alarms {
+		messages = append(messages, fmt.Sprintf("%s on member %x", alarm.Alarm, alarm.MemberID))
+	}
+
+	return &result{
+		conType: druidv1alpha1.ConditionTypeNoAlarms,
+		status:  druidv1alpha1.ConditionFalse,
+		reason:  "AlarmsRaised",
+		message: fmt.Sprintf("Active etcd alarms: %s", strings.Join(messages, "; ")),
+	}
+}
+
+// NoAlarmsCheck returns a check for the "NoAlarms" condition, which reports
+// False whenever the etcd cluster has an active NOSPACE, CORRUPT or
+// user-defined alarm raised against any member.
+func NoAlarmsCheck(clientBuilder etcdClientBuilder) Checker {
+	return &noAlarms{
+		clientBuilder: clientBuilder,
+	}
+}
+
+// DisarmNoSpaceAlarm clears a NOSPACE alarm on the given member once the
+// caller (typically the compaction/defrag workflow) has freed up space, so
+// that the NoAlarms condition can recover without manual intervention.
+func DisarmNoSpaceAlarm(ctx context.Context, cli *clientv3.Client, memberID uint64) error {
+	_, err := cli.AlarmDisarm(ctx, &clientv3.AlarmMember{
+		MemberID: memberID,
+		Alarm:    clientv3.AlarmNOSPACE,
+	})
+	return err
+}
+
+// AutoDisarmNoSpaceAlarms clears every active NOSPACE alarm on the given
+// Etcd cluster. It is meant to be invoked by the compaction/defrag workflow
+// right after it has freed up DB space, so that the NoAlarms condition
+// recovers automatically instead of requiring an operator to run
+// `etcdctl alarm disarm` by hand. Alarms other than NOSPACE are left
+// untouched, since clearing those (e.g. CORRUPT) without actually having
+// fixed the underlying issue would be actively harmful.
+func AutoDisarmNoSpaceAlarms(ctx context.Context, clientBuilder etcdClientBuilder, etcd druidv1alpha1.Etcd) error {
+	cli, err := clientBuilder.NewClient(ctx, etcd)
+	if err != nil {
+		return fmt.Errorf("creating etcd client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.AlarmList(ctx)
+	if err != nil {
+		return fmt.Errorf("listing etcd alarms: %w", err)
+	}
+
+	var errs []error
+	for _, alarm := range resp.Alarms {
+		if alarm.Alarm != clientv3.AlarmNOSPACE {
+			continue
+		}
+		if err := DisarmNoSpaceAlarm(ctx, cli, alarm.MemberID); err != nil {
+			errs = append(errs, fmt.Errorf("disarming NOSPACE alarm on member %x: %w", alarm.MemberID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type stubClientBuilder struct {
+	err error
+}
+
+func (s *stubClientBuilder) NewClient(_ context.Context, _ druidv1alpha1.Etcd) (*clientv3.Client, error) {
+	return nil, s.err
+}
+
+func TestNoAlarmsCheckClientCreationFailure(t *testing.T) {
+	check := NoAlarmsCheck(&stubClientBuilder{err: fmt.Errorf("dial tcp: connection refused")})
+
+	got, ok := check.Check(context.Background(), druidv1alpha1.Etcd{}).(*result)
+	if !ok {
+		t.Fatalf("Check() did not return a *result")
+	}
+	if got.conType != druidv1alpha1.ConditionTypeNoAlarms {
+		t.Errorf("conType = %q, want %q", got.conType, druidv1alpha1.ConditionTypeNoAlarms)
+	}
+	if got.status != druidv1alpha1.ConditionUnknown {
+		t.Errorf("status = %q, want %q", got.status, druidv1alpha1.ConditionUnknown)
+	}
+	if got.reason != "EtcdClientCreationFailed" {
+		t.Errorf("reason = %q, want %q", got.reason, "EtcdClientCreationFailed")
+	}
+}
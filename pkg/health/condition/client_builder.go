@@ -0,0 +1,104 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	"github.com/gardener/etcd-druid/internal/common"
+	"github.com/gardener/etcd-druid/internal/utils"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultClientDialTimeout = 5 * time.Second
+	defaultCASecretDataKey   = "ca.crt"
+)
+
+// clientBuilder is the concrete etcdClientBuilder used outside of tests. It
+// dials the cluster's peer service on the client port and, when ClientUrlTLS
+// is configured, trusts the CA referenced by TLSCASecretRef to verify the
+// server certificate. It does not yet present a client certificate of its
+// own, so it cannot reach a cluster whose client port requires mTLS client
+// authentication from callers other than etcd's own peers.
+type clientBuilder struct {
+	client client.Client
+}
+
+// NewClientBuilder returns an etcdClientBuilder that resolves the CA for a
+// cluster's client endpoint TLS through the Kubernetes API server reachable
+// via c.
+func NewClientBuilder(c client.Client) etcdClientBuilder {
+	return &clientBuilder{client: c}
+}
+
+func (b *clientBuilder) NewClient(ctx context.Context, etcd druidv1alpha1.Etcd) (*clientv3.Client, error) {
+	scheme := "http"
+	var tlsConfig *tls.Config
+	if etcd.Spec.Etcd.ClientUrlTLS != nil {
+		scheme = "https"
+		var err error
+		tlsConfig, err = b.tlsConfigFor(ctx, etcd.Namespace, etcd.Spec.Etcd.ClientUrlTLS.TLSCASecretRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clientPort := utils.TypeDeref(etcd.Spec.Etcd.ClientPort, common.DefaultPortEtcdClient)
+	endpoint := fmt.Sprintf("%s://%s.%s:%d", scheme, druidv1alpha1.GetPeerServiceName(etcd.ObjectMeta), etcd.Namespace, clientPort)
+
+	return clientv3.New(clientv3.Config{
+		Context:     ctx,
+		Endpoints:   []string{endpoint},
+		DialTimeout: defaultClientDialTimeout,
+		TLS:         tlsConfig,
+	})
+}
+
+// tlsConfigFor builds a *tls.Config trusting only the CA certificate stored
+// in the given SecretReference.
+func (b *clientBuilder) tlsConfigFor(ctx context.Context, namespace string, ref druidv1alpha1.SecretReference) (*tls.Config, error) {
+	secretNamespace := namespace
+	if ref.Namespace != "" {
+		secretNamespace = ref.Namespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := b.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: secretNamespace}, secret); err != nil {
+		return nil, fmt.Errorf("fetching CA secret %s/%s: %w", secretNamespace, ref.Name, err)
+	}
+
+	caKey := utils.TypeDeref(ref.DataKey, defaultCASecretDataKey)
+	caCert, ok := secret.Data[caKey]
+	if !ok {
+		return nil, fmt.Errorf("CA secret %s/%s has no data key %q", secretNamespace, ref.Name, caKey)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("CA secret %s/%s data key %q does not contain a valid PEM certificate", secretNamespace, ref.Name, caKey)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
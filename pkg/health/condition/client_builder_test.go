@@ -0,0 +1,106 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"context"
+	"testing"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testCACert = `-----BEGIN CERTIFICATE-----
+MIIBODCB66ADAgECAhQL1KyIzg4Ev/l0aoM88eN1IO1/rzAFBgMrZXAwEjEQMA4G
+A1UEAwwHdGVzdC1jYTAeFw0yNjA3MjYyMzUzMzBaFw0zNjA3MjMyMzUzMzBaMBIx
+EDAOBgNVBAMMB3Rlc3QtY2EwKjAFBgMrZXADIQAavoX8CJ7DPcYUDiNYdP+26EiF
+VZG/II0cQ1VXzzegzqNTMFEwHQYDVR0OBBYEFP0HD8WXQ2P3JtM5nC2twbYlm1Rh
+MB8GA1UdIwQYMBaAFP0HD8WXQ2P3JtM5nC2twbYlm1RhMA8GA1UdEwEB/wQFMAMB
+Af8wBQYDK2VwA0EA07H4dfVITVRBB+D7ehpoCSBdDgYcA7tNC9I0CPM3xzcEDYn/
+XK2smENLorDCu54E1KmGAJPwItIhsPRHEl0OCg==
+-----END CERTIFICATE-----
+`
+
+func TestTLSConfigForMissingSecret(t *testing.T) {
+	c := fakeclient.NewClientBuilder().Build()
+	b := &clientBuilder{client: c}
+
+	_, err := b.tlsConfigFor(context.Background(), "default", druidv1alpha1.SecretReference{Name: "missing-ca"})
+	if err == nil {
+		t.Errorf("expected an error for a missing CA secret, got nil")
+	}
+}
+
+func TestTLSConfigForMissingDataKey(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-ca", Namespace: "default"},
+		Data:       map[string][]byte{"other-key": []byte(testCACert)},
+	}
+	c := fakeclient.NewClientBuilder().WithObjects(secret).Build()
+	b := &clientBuilder{client: c}
+
+	_, err := b.tlsConfigFor(context.Background(), "default", druidv1alpha1.SecretReference{Name: "etcd-ca"})
+	if err == nil {
+		t.Errorf("expected an error when the default data key %q is absent, got nil", defaultCASecretDataKey)
+	}
+}
+
+func TestTLSConfigForInvalidPEM(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-ca", Namespace: "default"},
+		Data:       map[string][]byte{defaultCASecretDataKey: []byte("not a certificate")},
+	}
+	c := fakeclient.NewClientBuilder().WithObjects(secret).Build()
+	b := &clientBuilder{client: c}
+
+	_, err := b.tlsConfigFor(context.Background(), "default", druidv1alpha1.SecretReference{Name: "etcd-ca"})
+	if err == nil {
+		t.Errorf("expected an error for a non-PEM CA secret, got nil")
+	}
+}
+
+func TestTLSConfigForValidCA(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-ca", Namespace: "default"},
+		Data:       map[string][]byte{defaultCASecretDataKey: []byte(testCACert)},
+	}
+	c := fakeclient.NewClientBuilder().WithObjects(secret).Build()
+	b := &clientBuilder{client: c}
+
+	cfg, err := b.tlsConfigFor(context.Background(), "default", druidv1alpha1.SecretReference{Name: "etcd-ca"})
+	if err != nil {
+		t.Fatalf("tlsConfigFor() returned an unexpected error: %v", err)
+	}
+	if cfg == nil || cfg.RootCAs == nil {
+		t.Errorf("expected a *tls.Config with RootCAs populated from the CA secret")
+	}
+}
+
+func TestTLSConfigForUsesRefNamespaceOverride(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-ca", Namespace: "other-namespace"},
+		Data:       map[string][]byte{defaultCASecretDataKey: []byte(testCACert)},
+	}
+	c := fakeclient.NewClientBuilder().WithObjects(secret).Build()
+	b := &clientBuilder{client: c}
+
+	_, err := b.tlsConfigFor(context.Background(), "default", druidv1alpha1.SecretReference{Name: "etcd-ca", Namespace: "other-namespace"})
+	if err != nil {
+		t.Errorf("expected tlsConfigFor() to resolve the secret in ref.Namespace, got error: %v", err)
+	}
+}
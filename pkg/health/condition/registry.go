@@ -0,0 +1,32 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// DefaultChecks returns the full set of condition Checkers the etcd
+// controller evaluates on every reconcile, in the order their conditions
+// should be reported in Etcd.Status.Conditions. c is used both for checks
+// that read other objects from the Kubernetes API directly (AllMembersCheck,
+// CompactionSucceededCheck) and, via NewClientBuilder, for checks that need
+// to reach the etcd cluster itself (NoAlarmsCheck).
+func DefaultChecks(c client.Client) []Checker {
+	return []Checker{
+		AllMembersCheck(c),
+		NoAlarmsCheck(NewClientBuilder(c)),
+		CompactionSucceededCheck(c),
+		ConfigAppliedCheck(),
+	}
+}
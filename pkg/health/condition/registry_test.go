@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package condition
+
+import (
+	"testing"
+
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultChecks(t *testing.T) {
+	c := fakeclient.NewClientBuilder().Build()
+
+	checks := DefaultChecks(c)
+
+	wantConditions := []string{"AllMembersReady", "NoAlarms", "CompactionSucceeded", "ConfigApplied"}
+	if len(checks) != len(wantConditions) {
+		t.Fatalf("DefaultChecks() returned %d checks, want %d", len(checks), len(wantConditions))
+	}
+	for _, check := range checks {
+		if check == nil {
+			t.Errorf("DefaultChecks() contains a nil Checker")
+		}
+	}
+}